@@ -0,0 +1,49 @@
+# toolskel.bash
+# Bash completion for toolskel
+
+_toolskel() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	local prev="${COMP_WORDS[COMP_CWORD-1]}"
+	local flags=(
+		-author
+		-for-type
+		-list-types
+		-make-flavor
+		-self-completion
+		-shell
+		-type
+	)
+
+	# Complete flags' arguments, falling back to files.
+	case "$prev" in
+	-author)
+		return
+		;;
+	-for-type)
+		COMPREPLY=($(compgen -W "completion dockerfile fuzz library makefile manpage parallel periodic rcd readme simple systemd test" -- "$cur"))
+		return
+		;;
+	-make-flavor)
+		COMPREPLY=($(compgen -W "bsd gnu just" -- "$cur"))
+		return
+		;;
+	-self-completion)
+		COMPREPLY=($(compgen -W "bash fish zsh" -- "$cur"))
+		return
+		;;
+	-shell)
+		COMPREPLY=($(compgen -W "bash fish zsh" -- "$cur"))
+		return
+		;;
+	-type)
+		COMPREPLY=($(compgen -W "completion dockerfile fuzz library makefile manpage parallel periodic rcd readme simple systemd test" -- "$cur"))
+		return
+		;;
+	esac
+
+	# Complete flags themselves.
+	if [[ "$cur" == -* ]]; then
+		COMPREPLY=($(compgen -W "${flags[*]}" -- "$cur"))
+	fi
+}
+complete -o default -F _toolskel toolskel
@@ -0,0 +1,10 @@
+# toolskel.fish
+# Fish completion for toolskel
+
+complete -c toolskel -o author -r -d 'Author\'s name'
+complete -c toolskel -o for-type -x -a 'completion dockerfile fuzz library makefile manpage parallel periodic rcd readme simple systemd test' -d 'Tool type for -type test'
+complete -c toolskel -o list-types -d 'List available tool types'
+complete -c toolskel -o make-flavor -x -a 'bsd gnu just' -d 'Makefile flavor'
+complete -c toolskel -o self-completion -x -a 'bash fish zsh' -d 'Print a completion shell'
+complete -c toolskel -o shell -x -a 'bash fish zsh' -d 'Completion script shell'
+complete -c toolskel -o type -x -a 'completion dockerfile fuzz library makefile manpage parallel periodic rcd readme simple systemd test' -d 'Tool type (see -list-types)'
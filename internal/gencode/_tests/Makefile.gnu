@@ -0,0 +1,112 @@
+# Makefile
+# Build cooltool
+# By MysteryDev
+# Created in the past
+# Last Modified in the past
+
+BINNAME       := $(notdir $(CURDIR))
+BUILDTIME     := $(shell date -u +%Y-%m-%dT%H:%M:%SZ)
+VERSION       := $(shell git describe --always --dirty 2>/dev/null || true)
+LDFLAGS        = -w -s -X main.BuildTime=${BUILDTIME}
+ifneq (${VERSION},)
+LDFLAGS       += -X main.Version=${VERSION}
+endif
+BUILDFLAGS     = -trimpath -ldflags "${LDFLAGS}"
+FUZZTIME      ?= 10s
+MANDIR        ?= /usr/local/man
+SHA256SUM     ?= sha256sum
+SRCS          := $(shell find . -path ./bin -prune -o \( -type f -o -type d \) -print)
+SRCS          := $(filter-out ./$(BINNAME),$(SRCS))
+TESTFLAGS     += -timeout 3s
+VETFLAGS       = -printf.funcs 'debugf,errorf,erorrlogf,logf,printf'
+
+.PHONY: all test fuzz readme release install install-man clean
+
+all: test build
+
+${BINNAME}: ${SRCS}
+	go build ${BUILDFLAGS} -o ${BINNAME}
+
+build: ${BINNAME}
+
+bin/${BINNAME}-darwin-amd64: ${SRCS}
+	GOOS=darwin GOARCH=amd64 go build ${BUILDFLAGS} -o $@
+
+bin/${BINNAME}-darwin-arm64: ${SRCS}
+	GOOS=darwin GOARCH=arm64 go build ${BUILDFLAGS} -o $@
+
+bin/${BINNAME}-linux-amd64: ${SRCS}
+	GOOS=linux GOARCH=amd64 go build ${BUILDFLAGS} -o $@
+
+bin/${BINNAME}-linux-arm64: ${SRCS}
+	GOOS=linux GOARCH=arm64 go build ${BUILDFLAGS} -o $@
+
+bin/${BINNAME}-openbsd-amd64: ${SRCS}
+	GOOS=openbsd GOARCH=amd64 go build ${BUILDFLAGS} -o $@
+
+bin/${BINNAME}-windows-amd64.exe: ${SRCS}
+	GOOS=windows GOARCH=amd64 go build ${BUILDFLAGS} -o $@
+
+release:\
+	bin/${BINNAME}-darwin-amd64\
+	bin/${BINNAME}-darwin-arm64\
+	bin/${BINNAME}-linux-amd64\
+	bin/${BINNAME}-linux-arm64\
+	bin/${BINNAME}-openbsd-amd64\
+	bin/${BINNAME}-windows-amd64.exe
+	cd bin && ${SHA256SUM}\
+		${BINNAME}-darwin-amd64\
+		${BINNAME}-darwin-arm64\
+		${BINNAME}-linux-amd64\
+		${BINNAME}-linux-arm64\
+		${BINNAME}-openbsd-amd64\
+		${BINNAME}-windows-amd64.exe\
+		> SHA256SUMS
+
+test:
+	go test ${BUILDFLAGS} ${TESTFLAGS} -short ./...
+	go vet  ${BUILDFLAGS} ${VETFLAGS} ./...
+	staticcheck ./...
+	go run ${BUILDFLAGS} . -h 2>&1 |\
+	awk '\
+		/^Options:$$|MQD DEBUG PACKAGE LOADED$$/\
+			{ exit }\
+		/^Usage: /\
+			{ sub(/^Usage: [^[:space:]]+\//, "Usage: ") }\
+		/.{80,}/\
+			{ print "Long usage line: " $0; exit 1 }\
+	'
+
+fuzz:
+	for f in $$(go test -list '^Fuzz' . | grep '^Fuzz'); do\
+		go test ${BUILDFLAGS} -run '^$$' -fuzz "^$$f$$"\
+			-fuzztime ${FUZZTIME} . || exit 1;\
+	done
+
+readme: ${BINNAME}
+	./${BINNAME} -h 2>${BINNAME}.usage
+	awk '\
+		NR == FNR\
+			{ sub(/^Usage: [^[:space:]]+\//, "Usage: ") }\
+		NR == FNR\
+			{ usage = usage $$0 "\n"; next }\
+		/^<!-- \/usage -->$$/\
+			{ printf "```\n%s```\n", usage; skip = 0 }\
+		!skip\
+			{ print }\
+		/^<!-- usage -->$$/\
+			{ skip = 1 }\
+	' ${BINNAME}.usage README.md > README.md.new
+	mv README.md.new README.md
+	rm ${BINNAME}.usage
+
+install:
+	go install ${BUILDFLAGS}
+
+install-man: ${BINNAME}.1
+	install -d ${MANDIR}/man1
+	install -m 0444 ${BINNAME}.1 ${MANDIR}/man1
+
+clean:
+	rm -f ${BINNAME}
+	rm -rf bin
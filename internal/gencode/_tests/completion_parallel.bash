@@ -0,0 +1,39 @@
+# cooltool.bash
+# Bash completion for cooltool
+# By MysteryDev
+# Created in the past
+# Last Modified in the past
+
+_cooltool() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	local prev="${COMP_WORDS[COMP_CWORD-1]}"
+	local flags=(
+		-max-errors
+		-no-summary
+		-parallel
+		-state
+		-task-timeout
+	)
+
+	# Complete flags' arguments, falling back to files.
+	case "$prev" in
+	-max-errors)
+		return
+		;;
+	-parallel)
+		return
+		;;
+	-state)
+		return
+		;;
+	-task-timeout)
+		return
+		;;
+	esac
+
+	# Complete flags themselves.
+	if [[ "$cur" == -* ]]; then
+		COMPREPLY=($(compgen -W "${flags[*]}" -- "$cur"))
+	fi
+}
+complete -o default -F _cooltool cooltool
@@ -0,0 +1,10 @@
+# cooltool.fish
+# Fish completion for cooltool
+# By MysteryDev
+# Created in the past
+# Last Modified in the past
+
+complete -c cooltool -o count -r -d 'Number of runs to make, or 0 for no limit'
+complete -c cooltool -o interval -r -d 'Run interval'
+complete -c cooltool -o jitter -r -d 'Maximum random delay added to each run'
+complete -c cooltool -o no-summary -d 'Don\'t print a summary on exit'
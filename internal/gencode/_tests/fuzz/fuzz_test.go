@@ -0,0 +1,31 @@
+package main
+
+/*
+ * fuzz_test.go
+ * Fuzz test and benchmark for Parse
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import "testing"
+
+// FuzzParse fuzzes Parse.  More seeds may be added to
+// testdata/fuzz/FuzzParse.
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{"", "\x00\xff", "moose"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		/* TODO: Check more than that it doesn't panic. */
+		Parse(s)
+	})
+}
+
+// BenchmarkParse benchmarks Parse.
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		Parse("kittens")
+	}
+}
@@ -0,0 +1,31 @@
+package cooltool
+
+/*
+ * fuzz_test.go
+ * Fuzz test and benchmark for parseLine
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import "testing"
+
+// FuzzParseLine fuzzes parseLine.  More seeds may be added to
+// testdata/fuzz/FuzzParseLine.
+func FuzzParseLine(f *testing.F) {
+	for _, seed := range []string{"", "\x00\xff", "moose"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		/* TODO: Check more than that it doesn't panic. */
+		parseLine(s)
+	})
+}
+
+// BenchmarkParseLine benchmarks parseLine.
+func BenchmarkParseLine(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		parseLine("kittens")
+	}
+}
@@ -1,16 +0,0 @@
-// Package main - A cool program
-package main
-
-/*
- * main.go
- * A cool program
- * By MysteryDev
- * Created in the past
- * Last Modified in the past
- */
-
-import (
-	"fmt"
-)
-
-func main() { fmt.Printf("Delete this, as this is a library") }
@@ -0,0 +1,44 @@
+package cooltool
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"time"
+)
+
+// DefaultTimeout is the default amount of time a Thing waits before giving up.
+const DefaultTimeout = time.Minute
+
+// Thing does something useful.  Create one with New.
+type Thing struct {
+	timeout time.Duration
+}
+
+// Option configures a Thing.  Options are passed to New.
+type Option func(t *Thing)
+
+// WithTimeout sets how long a Thing waits before giving up.
+func WithTimeout(d time.Duration) Option {
+	return func(t *Thing) { t.timeout = d }
+}
+
+// New returns a new Thing, configured with opts.
+func New(opts ...Option) *Thing {
+	t := &Thing{timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Do does the thing with s.
+func (t *Thing) Do(s string) (string, error) {
+	/* TODO: Something useful. */
+	return s, nil
+}
@@ -0,0 +1,34 @@
+package cooltool
+
+/*
+ * cooltool_test.go
+ * Tests for cooltool.go
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import "testing"
+
+func TestThingDo(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		have string
+		want string
+	}{{
+		name: "kittens",
+		have: "kittens",
+		want: "kittens",
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := New().Do(c.have)
+			if nil != err {
+				t.Fatalf("Error: %s", err)
+			}
+			if got != c.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, c.want)
+			}
+		})
+	}
+}
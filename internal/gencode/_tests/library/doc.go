@@ -0,0 +1,12 @@
+// Package cooltool - A cool program
+//
+// TODO: Describe the package in more detail.
+package cooltool
+
+/*
+ * doc.go
+ * Package documentation
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
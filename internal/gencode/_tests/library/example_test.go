@@ -0,0 +1,27 @@
+package cooltool
+
+/*
+ * example_test.go
+ * Examples of using cooltool
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+func ExampleThing_Do() {
+	t := New(WithTimeout(time.Second))
+	s, err := t.Do("kittens")
+	if nil != err {
+		log.Fatalf("Error: %s", err)
+	}
+	fmt.Println(s)
+
+	// Output:
+	// kittens
+}
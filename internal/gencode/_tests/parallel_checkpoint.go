@@ -0,0 +1,279 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+
+	/* NSkipped keeps track of the number of tasks skipped because they
+	finished in a previous run. */
+	NSkipped atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+	/* Skip tasks which finished in previous runs, if not nil. */
+	cp *checkpoint
+}
+
+// checkpoint keeps track of finished tasks in a file, so they can be skipped
+// in later runs.  A nil checkpoint keeps track of nothing.
+type checkpoint struct {
+	prev map[string]struct{} /* Keys of tasks from previous runs. */
+	mu   sync.Mutex
+	f    *os.File
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+		stateFile = flag.String(
+			"state",
+			"",
+			"Note finished tasks in `file` and skip them next run",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+	}
+
+	/* Skip tasks which are already done, if we're keeping track. */
+	if "" != *stateFile {
+		var err error
+		if ec.cp, err = openCheckpoint(*stateFile); nil != err {
+			log.Fatalf("Error opening checkpoint file: %s", err)
+		}
+	}
+
+	/* Start some task executors. */
+	var (
+		ch = make(chan Task)
+		wg sync.WaitGroup
+	)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ch, &wg, ec)
+	}
+
+	/* Send the tasks to be executed. */
+	tasks, err := getTasks()
+	if nil != err {
+		log.Fatalf("Error getting tasks: %s", err)
+	}
+	for _, task := range tasks {
+		if ec.gaveUp() {
+			break
+		}
+		ch <- task
+	}
+
+	/* Wait for the executors to finish executing. */
+	close(ch)
+	wg.Wait()
+
+	/* Make sure all the finished tasks are noted. */
+	if err := ec.cp.close(); nil != err {
+		log.Printf("Error closing checkpoint file: %s", err)
+	}
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done in %s (%d failed, %d skipped).",
+			time.Since(ProgramStart).Round(time.Millisecond),
+			NFailed.Load(),
+			NSkipped.Load(),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+/* getTasks returns a list of tasks to execute. */
+func getTasks() ([]Task, error) {
+	return make([]Task, 0), nil
+}
+
+// Key returns a string which identifies t between runs, for checkpointing.
+// Tasks with an empty key are never skipped.  Keys may not contain newlines.
+func (t Task) Key() string {
+	/* TODO: Work out something which uniquely identifies t. */
+	return ""
+}
+
+/* taskExecutor executes the tasks sent on ch. */
+func taskExecutor(ch <-chan Task, wg *sync.WaitGroup, ec execConfig) {
+	defer wg.Done()
+	for t := range ch {
+		runTask(t, ec)
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// true if t was executed successfully.
+func runTask(t Task, ec execConfig) bool {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return false
+	}
+
+	/* Don't redo what's already done. */
+	key := t.Key()
+	if ec.cp.finishedBefore(key) {
+		NSkipped.Add(1)
+		return false
+	}
+
+	/* Do the thing and note if it didn't work. */
+	err := executeTask(t)
+	if nil == err {
+		if err := ec.cp.finished(key); nil != err {
+			log.Printf("Error checkpointing task %q: %s", key, err)
+		}
+		return true
+	}
+	log.Printf("Task failed: %s", err)
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+	}
+	return false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* executeTask executes a single task. */
+func executeTask(t Task) error {
+	log.Printf("Executing a task")
+	return nil
+}
+
+// openCheckpoint reads the keys of tasks finished in previous runs from the
+// named file, which may not exist, and opens it for noting more.
+func openCheckpoint(name string) (*checkpoint, error) {
+	cp := &checkpoint{prev: make(map[string]struct{})}
+
+	/* Get the keys from last time. */
+	f, err := os.Open(name)
+	if nil == err {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			cp.prev[scanner.Text()] = struct{}{}
+		}
+		if err := scanner.Err(); nil != err {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	/* Open the file for this time's keys. */
+	if cp.f, err = os.OpenFile(
+		name,
+		os.O_WRONLY|os.O_APPEND|os.O_CREATE,
+		0600,
+	); nil != err {
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+/* finishedBefore returns true if key finished in a previous run. */
+func (cp *checkpoint) finishedBefore(key string) bool {
+	if nil == cp || "" == key {
+		return false
+	}
+	_, ok := cp.prev[key]
+	return ok
+}
+
+/* finished notes that the task with the given key has finished. */
+func (cp *checkpoint) finished(key string) error {
+	if nil == cp || "" == key {
+		return nil
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	_, err := fmt.Fprintln(cp.f, key)
+	return err
+}
+
+/* close closes cp's underlying file. */
+func (cp *checkpoint) close() error {
+	if nil == cp {
+		return nil
+	}
+	return cp.f.Close()
+}
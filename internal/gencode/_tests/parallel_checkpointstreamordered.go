@@ -0,0 +1,375 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NDone keeps track of the number of things we've done. */
+	NDone atomic.Uint64
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+
+	/* NTimedOut keeps track of the number of failed tasks which timed
+	out. */
+	NTimedOut atomic.Uint64
+
+	/* NSkipped keeps track of the number of tasks skipped because they
+	finished in a previous run. */
+	NSkipped atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct {
+	seq uint64 /* Sequence number, for ordering results. */
+}
+
+// Result is the result of executing a Task.
+type Result struct {
+	seq  uint64 /* Task's sequence number. */
+	skip bool   /* Task failed or was skipped; nothing to write. */
+}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+	/* Closed when maxErrors tasks have failed, to stop reading tasks. */
+	giveUp chan struct{}
+	/* Give each task this long to finish, if nonzero. */
+	timeout time.Duration
+	/* Skip tasks which finished in previous runs, if not nil. */
+	cp *checkpoint
+}
+
+// checkpoint keeps track of finished tasks in a file, so they can be skipped
+// in later runs.  A nil checkpoint keeps track of nothing.
+type checkpoint struct {
+	prev map[string]struct{} /* Keys of tasks from previous runs. */
+	mu   sync.Mutex
+	f    *os.File
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+		taskTimeout = flag.Duration(
+			"task-timeout",
+			0,
+			"Per-task `timeout` (0 for none)",
+		)
+		stateFile = flag.String(
+			"state",
+			"",
+			"Note finished tasks in `file` and skip them next run",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+		giveUp:    make(chan struct{}),
+		timeout:   *taskTimeout,
+	}
+
+	/* Skip tasks which are already done, if we're keeping track. */
+	if "" != *stateFile {
+		var err error
+		if ec.cp, err = openCheckpoint(*stateFile); nil != err {
+			log.Fatalf("Error opening checkpoint file: %s", err)
+		}
+	}
+
+	/* Start some task executors and something to write their results. */
+	var (
+		ch      = make(chan Task)
+		results = make(chan Result)
+		wDone   = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	go resultWriter(results, wDone)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ch, results, &wg, ec)
+	}
+
+	/* Send the tasks to be executed as they're read. */
+	gtErr := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		gtErr <- getTasks(ch, ec.giveUp)
+	}()
+
+	/* Wait for the executors to finish executing and make sure we got
+	all of the tasks. */
+	wg.Wait()
+	taskErr := <-gtErr
+	if nil != taskErr {
+		log.Printf("Error getting tasks: %s", taskErr)
+	}
+
+	/* Wait for the last of the results to be written. */
+	close(results)
+	<-wDone
+
+	/* Make sure all the finished tasks are noted. */
+	if err := ec.cp.close(); nil != err {
+		log.Printf("Error closing checkpoint file: %s", err)
+	}
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done.  Finished %d (%d failed, %d timed out, %d skipped) in %s.",
+			NDone.Load(),
+			NFailed.Load(),
+			NTimedOut.Load(),
+			NSkipped.Load(),
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if nil != taskErr || 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+// getTasks sends tasks read from stdin to ch.  It returns at the end of stdin,
+// on error, or when giveUp is closed.
+func getTasks(ch chan<- Task, giveUp <-chan struct{}) error {
+	scanner := bufio.NewScanner(os.Stdin)
+	for n := uint64(0); scanner.Scan(); n++ {
+		/* TODO: Turn scanner.Text() into a Task. */
+		select {
+		case ch <- Task{seq: n}:
+		case <-giveUp:
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// Key returns a string which identifies t between runs, for checkpointing.
+// Tasks with an empty key are never skipped.  Keys may not contain newlines.
+func (t Task) Key() string {
+	/* TODO: Work out something which uniquely identifies t. */
+	return ""
+}
+
+/* taskExecutor executes the tasks sent on ch and sends the results. */
+func taskExecutor(
+	ch <-chan Task,
+	results chan<- Result,
+	wg *sync.WaitGroup,
+	ec execConfig,
+) {
+	defer wg.Done()
+	for t := range ch {
+		/* Send something even if the task failed, so the writer
+		doesn't wait for it. */
+		r, ok := runTask(t, ec)
+		r.seq = t.seq
+		r.skip = !ok
+		results <- r
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// t's result and true if t was executed successfully.
+func runTask(t Task, ec execConfig) (Result, bool) {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return Result{}, false
+	}
+
+	/* Don't redo what's already done. */
+	key := t.Key()
+	if ec.cp.finishedBefore(key) {
+		NSkipped.Add(1)
+		return Result{}, false
+	}
+
+	/* Give the task a deadline, if it should have one. */
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if 0 != ec.timeout {
+		ctx, cancel = context.WithTimeout(ctx, ec.timeout)
+		defer cancel()
+	}
+
+	/* Do the thing and note if it didn't work. */
+	r, err := executeTask(ctx, t)
+	if nil == err {
+		if err := ec.cp.finished(key); nil != err {
+			log.Printf("Error checkpointing task %q: %s", key, err)
+		}
+		return r, true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Task timed out: %s", err)
+		NTimedOut.Add(1)
+	} else {
+		log.Printf("Task failed: %s", err)
+	}
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+		close(ec.giveUp)
+	}
+	return Result{}, false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* executeTask executes a single task. */
+func executeTask(ctx context.Context, t Task) (Result, error) {
+	defer NDone.Add(1)
+	log.Printf("Executing a task")
+	return Result{}, nil
+}
+
+// resultWriter writes the results sent on ch to stdout in the order in which
+// their tasks were sent, holding on to results which finish early.  It closes
+// done when ch is closed and all results have been written.
+func resultWriter(ch <-chan Result, done chan<- struct{}) {
+	defer close(done)
+	var (
+		next    uint64
+		pending = make(map[uint64]Result)
+	)
+	for r := range ch {
+		pending[r.seq] = r
+		for r, ok := pending[next]; ok; r, ok = pending[next] {
+			delete(pending, next)
+			if !r.skip {
+				writeResult(r)
+			}
+			next++
+		}
+	}
+}
+
+/* writeResult writes a single result to stdout. */
+func writeResult(r Result) {
+	fmt.Printf("%+v\n", r)
+}
+
+// openCheckpoint reads the keys of tasks finished in previous runs from the
+// named file, which may not exist, and opens it for noting more.
+func openCheckpoint(name string) (*checkpoint, error) {
+	cp := &checkpoint{prev: make(map[string]struct{})}
+
+	/* Get the keys from last time. */
+	f, err := os.Open(name)
+	if nil == err {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			cp.prev[scanner.Text()] = struct{}{}
+		}
+		if err := scanner.Err(); nil != err {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	/* Open the file for this time's keys. */
+	if cp.f, err = os.OpenFile(
+		name,
+		os.O_WRONLY|os.O_APPEND|os.O_CREATE,
+		0600,
+	); nil != err {
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+/* finishedBefore returns true if key finished in a previous run. */
+func (cp *checkpoint) finishedBefore(key string) bool {
+	if nil == cp || "" == key {
+		return false
+	}
+	_, ok := cp.prev[key]
+	return ok
+}
+
+/* finished notes that the task with the given key has finished. */
+func (cp *checkpoint) finished(key string) error {
+	if nil == cp || "" == key {
+		return nil
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	_, err := fmt.Fprintln(cp.f, key)
+	return err
+}
+
+/* close closes cp's underlying file. */
+func (cp *checkpoint) close() error {
+	if nil == cp {
+		return nil
+	}
+	return cp.f.Close()
+}
@@ -0,0 +1,265 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+}
+
+// Config holds settings which may be loaded from a JSON file with -config.
+// Its fields point to the corresponding flags' values.
+type Config struct {
+	/* Don't print a summary on exit. */
+	NoSummary *bool `json:"no-summary"`
+	/* Parallel task execution count. */
+	Parallel *uint `json:"parallel"`
+	/* Give up after this many failed tasks, or 0 for no limit. */
+	MaxErrors *uint64 `json:"max-errors"`
+}
+
+// jsonDuration is a time.Duration which is represented in JSON as a string
+// understood by time.ParseDuration.
+type jsonDuration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *jsonDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); nil != err {
+		return err
+	}
+	pd, err := time.ParseDuration(s)
+	if nil != err {
+		return err
+	}
+	*d = jsonDuration(pd)
+	return nil
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		configFile = flag.String(
+			"config",
+			"",
+			"Load settings from JSON `file`",
+		)
+		printCfg = flag.Bool(
+			"print-config",
+			false,
+			"Print the effective settings as JSON and exit",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Settings may also come from a config file, but flags given on the
+	command line take precedence. */
+	cfg := Config{
+		NoSummary: noSummary,
+		Parallel:  nPar,
+		MaxErrors: maxErrors,
+	}
+	if "" != *configFile {
+		if err := loadConfig(&cfg, *configFile); nil != err {
+			log.Fatalf(
+				"Error loading config from %s: %s",
+				*configFile,
+				err,
+			)
+		}
+	}
+	if *printCfg {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(cfg); nil != err {
+			log.Fatalf("Error printing config: %s", err)
+		}
+		return
+	}
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+	}
+
+	/* Start some task executors. */
+	var (
+		ch = make(chan Task)
+		wg sync.WaitGroup
+	)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ch, &wg, ec)
+	}
+
+	/* Send the tasks to be executed. */
+	tasks, err := getTasks()
+	if nil != err {
+		log.Fatalf("Error getting tasks: %s", err)
+	}
+	for _, task := range tasks {
+		if ec.gaveUp() {
+			break
+		}
+		ch <- task
+	}
+
+	/* Wait for the executors to finish executing. */
+	close(ch)
+	wg.Wait()
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done in %s (%d failed).",
+			time.Since(ProgramStart).Round(time.Millisecond),
+			NFailed.Load(),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+/* getTasks returns a list of tasks to execute. */
+func getTasks() ([]Task, error) {
+	return make([]Task, 0), nil
+}
+
+/* taskExecutor executes the tasks sent on ch. */
+func taskExecutor(ch <-chan Task, wg *sync.WaitGroup, ec execConfig) {
+	defer wg.Done()
+	for t := range ch {
+		runTask(t, ec)
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// true if t was executed successfully.
+func runTask(t Task, ec execConfig) bool {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return false
+	}
+
+	/* Do the thing and note if it didn't work. */
+	err := executeTask(t)
+	if nil == err {
+		return true
+	}
+	log.Printf("Task failed: %s", err)
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+	}
+	return false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* executeTask executes a single task. */
+func executeTask(t Task) error {
+	log.Printf("Executing a task")
+	return nil
+}
+
+// loadConfig loads the JSON config file named name into cfg.  Unknown fields
+// are an error.  Flags given on the command line keep their values.
+func loadConfig(cfg *Config, name string) error {
+	/* Note what was set on the command line, to put back later. */
+	set := make(map[*flag.Flag]string)
+	flag.Visit(func(f *flag.Flag) { set[f] = f.Value.String() })
+
+	/* Load the file. */
+	f, err := os.Open(name)
+	if nil != err {
+		return err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); nil != err {
+		return err
+	}
+
+	/* Command line wins. */
+	for f, v := range set {
+		if err := f.Value.Set(v); nil != err {
+			return fmt.Errorf("restoring -%s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
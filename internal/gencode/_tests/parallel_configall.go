@@ -0,0 +1,507 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+
+	/* NTimedOut keeps track of the number of failed tasks which timed
+	out. */
+	NTimedOut atomic.Uint64
+
+	/* NSkipped keeps track of the number of tasks skipped because they
+	finished in a previous run. */
+	NSkipped atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+	/* Wait for a token before starting each task, if not nil. */
+	tokens <-chan struct{}
+	/* Give each task this long to finish, if nonzero. */
+	timeout time.Duration
+	/* Retry retryable failures this many times. */
+	retries uint
+	/* Wait about this long before the first retry, doubling each time. */
+	backoff time.Duration
+	/* Skip tasks which finished in previous runs, if not nil. */
+	cp *checkpoint
+}
+
+// RetryableError wraps an error returned by executeTask to indicate the task
+// may be retried.
+type RetryableError struct{ Err error }
+
+// Error implements the error interface.
+func (err RetryableError) Error() string { return err.Err.Error() }
+
+// Unwrap returns the wrapped error.
+func (err RetryableError) Unwrap() error { return err.Err }
+
+// checkpoint keeps track of finished tasks in a file, so they can be skipped
+// in later runs.  A nil checkpoint keeps track of nothing.
+type checkpoint struct {
+	prev map[string]struct{} /* Keys of tasks from previous runs. */
+	mu   sync.Mutex
+	f    *os.File
+}
+
+// Config holds settings which may be loaded from a JSON file with -config.
+// Its fields point to the corresponding flags' values.
+type Config struct {
+	/* Don't print a summary on exit. */
+	NoSummary *bool `json:"no-summary"`
+	/* Parallel task execution count. */
+	Parallel *uint `json:"parallel"`
+	/* Give up after this many failed tasks, or 0 for no limit. */
+	MaxErrors *uint64 `json:"max-errors"`
+	/* Tasks per second, or 0 for no limit. */
+	Rate *float64 `json:"rate"`
+	/* Per-task timeout, or 0 for none. */
+	Timeout *jsonDuration `json:"task-timeout"`
+	/* Retry retryable task failures this many times. */
+	Retries *uint `json:"retries"`
+	/* Initial retry backoff, doubled each retry. */
+	Backoff *jsonDuration `json:"backoff"`
+	/* File in which to note finished tasks. */
+	StateFile *string `json:"state"`
+}
+
+// jsonDuration is a time.Duration which is represented in JSON as a string
+// understood by time.ParseDuration.
+type jsonDuration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *jsonDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); nil != err {
+		return err
+	}
+	pd, err := time.ParseDuration(s)
+	if nil != err {
+		return err
+	}
+	*d = jsonDuration(pd)
+	return nil
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		configFile = flag.String(
+			"config",
+			"",
+			"Load settings from JSON `file`",
+		)
+		printCfg = flag.Bool(
+			"print-config",
+			false,
+			"Print the effective settings as JSON and exit",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+		rate = flag.Float64(
+			"rate",
+			0,
+			"Limit to `rate` tasks per second (0 for no limit)",
+		)
+		taskTimeout = flag.Duration(
+			"task-timeout",
+			0,
+			"Per-task `timeout` (0 for none)",
+		)
+		retries = flag.Uint(
+			"retries",
+			0,
+			"Retry retryable task failures up to `count` times",
+		)
+		backoff = flag.Duration(
+			"backoff",
+			time.Second,
+			"Initial retry backoff `duration`, doubled each retry",
+		)
+		stateFile = flag.String(
+			"state",
+			"",
+			"Note finished tasks in `file` and skip them next run",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Settings may also come from a config file, but flags given on the
+	command line take precedence. */
+	cfg := Config{
+		NoSummary: noSummary,
+		Parallel:  nPar,
+		MaxErrors: maxErrors,
+		Rate:      rate,
+		Timeout:   (*jsonDuration)(taskTimeout),
+		Retries:   retries,
+		Backoff:   (*jsonDuration)(backoff),
+		StateFile: stateFile,
+	}
+	if "" != *configFile {
+		if err := loadConfig(&cfg, *configFile); nil != err {
+			log.Fatalf(
+				"Error loading config from %s: %s",
+				*configFile,
+				err,
+			)
+		}
+	}
+	if *printCfg {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(cfg); nil != err {
+			log.Fatalf("Error printing config: %s", err)
+		}
+		return
+	}
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+		tokens:    startTokenBucket(*rate),
+		timeout:   *taskTimeout,
+		retries:   *retries,
+		backoff:   *backoff,
+	}
+
+	/* Skip tasks which are already done, if we're keeping track. */
+	if "" != *stateFile {
+		var err error
+		if ec.cp, err = openCheckpoint(*stateFile); nil != err {
+			log.Fatalf("Error opening checkpoint file: %s", err)
+		}
+	}
+
+	/* Start some task executors. */
+	var (
+		ch = make(chan Task)
+		wg sync.WaitGroup
+	)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ch, &wg, ec)
+	}
+
+	/* Send the tasks to be executed. */
+	tasks, err := getTasks()
+	if nil != err {
+		log.Fatalf("Error getting tasks: %s", err)
+	}
+	for _, task := range tasks {
+		if ec.gaveUp() {
+			break
+		}
+		ch <- task
+	}
+
+	/* Wait for the executors to finish executing. */
+	close(ch)
+	wg.Wait()
+
+	/* Make sure all the finished tasks are noted. */
+	if err := ec.cp.close(); nil != err {
+		log.Printf("Error closing checkpoint file: %s", err)
+	}
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done in %s (%d failed, %d timed out, %d skipped).",
+			time.Since(ProgramStart).Round(time.Millisecond),
+			NFailed.Load(),
+			NTimedOut.Load(),
+			NSkipped.Load(),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+/* getTasks returns a list of tasks to execute. */
+func getTasks() ([]Task, error) {
+	return make([]Task, 0), nil
+}
+
+// Key returns a string which identifies t between runs, for checkpointing.
+// Tasks with an empty key are never skipped.  Keys may not contain newlines.
+func (t Task) Key() string {
+	/* TODO: Work out something which uniquely identifies t. */
+	return ""
+}
+
+/* taskExecutor executes the tasks sent on ch. */
+func taskExecutor(ch <-chan Task, wg *sync.WaitGroup, ec execConfig) {
+	defer wg.Done()
+	for t := range ch {
+		runTask(t, ec)
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// true if t was executed successfully.
+func runTask(t Task, ec execConfig) bool {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return false
+	}
+
+	/* Don't redo what's already done. */
+	key := t.Key()
+	if ec.cp.finishedBefore(key) {
+		NSkipped.Add(1)
+		return false
+	}
+
+	/* Do the thing, retrying if it's worth it. */
+	err := tryTask(t, ec)
+	for try := uint(1); try <= ec.retries; try++ {
+		if !errors.As(err, new(RetryableError)) {
+			break
+		}
+		d := ec.backoff << (try - 1)
+		d += rand.N(d/2 + 1)
+		log.Printf(
+			"Task failed, retry %d/%d in %s: %s",
+			try,
+			ec.retries,
+			d,
+			err,
+		)
+		time.Sleep(d)
+		err = tryTask(t, ec)
+	}
+
+	/* Note if it didn't work. */
+	if nil == err {
+		if err := ec.cp.finished(key); nil != err {
+			log.Printf("Error checkpointing task %q: %s", key, err)
+		}
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Task timed out: %s", err)
+		NTimedOut.Add(1)
+	} else {
+		log.Printf("Task failed: %s", err)
+	}
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+	}
+	return false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* tryTask makes a single attempt at executing t. */
+func tryTask(t Task, ec execConfig) error {
+	/* Don't go too fast. */
+	if nil != ec.tokens {
+		<-ec.tokens
+	}
+
+	/* Give the task a deadline, if it should have one. */
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if 0 != ec.timeout {
+		ctx, cancel = context.WithTimeout(ctx, ec.timeout)
+		defer cancel()
+	}
+
+	return executeTask(ctx, t)
+}
+
+/* executeTask executes a single task. */
+func executeTask(ctx context.Context, t Task) error {
+	log.Printf("Executing a task")
+	return nil
+}
+
+// openCheckpoint reads the keys of tasks finished in previous runs from the
+// named file, which may not exist, and opens it for noting more.
+func openCheckpoint(name string) (*checkpoint, error) {
+	cp := &checkpoint{prev: make(map[string]struct{})}
+
+	/* Get the keys from last time. */
+	f, err := os.Open(name)
+	if nil == err {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			cp.prev[scanner.Text()] = struct{}{}
+		}
+		if err := scanner.Err(); nil != err {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	/* Open the file for this time's keys. */
+	if cp.f, err = os.OpenFile(
+		name,
+		os.O_WRONLY|os.O_APPEND|os.O_CREATE,
+		0600,
+	); nil != err {
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+/* finishedBefore returns true if key finished in a previous run. */
+func (cp *checkpoint) finishedBefore(key string) bool {
+	if nil == cp || "" == key {
+		return false
+	}
+	_, ok := cp.prev[key]
+	return ok
+}
+
+/* finished notes that the task with the given key has finished. */
+func (cp *checkpoint) finished(key string) error {
+	if nil == cp || "" == key {
+		return nil
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	_, err := fmt.Fprintln(cp.f, key)
+	return err
+}
+
+/* close closes cp's underlying file. */
+func (cp *checkpoint) close() error {
+	if nil == cp {
+		return nil
+	}
+	return cp.f.Close()
+}
+
+// startTokenBucket returns a channel which receives perSec tokens per second,
+// holding up to a second's worth.  If perSec isn't positive, startTokenBucket
+// returns nil.  Rates above one token per nanosecond are treated as one token
+// per nanosecond.
+func startTokenBucket(perSec float64) <-chan struct{} {
+	if 0 >= perSec {
+		return nil
+	}
+	perSec = min(perSec, float64(time.Second))
+	var (
+		ch    = make(chan struct{}, max(1, int(perSec)))
+		every = max(time.Duration(float64(time.Second)/perSec), 1)
+	)
+	go func() {
+		for range time.Tick(every) {
+			select {
+			case ch <- struct{}{}:
+			default: /* Bucket's full. */
+			}
+		}
+	}()
+	return ch
+}
+
+// loadConfig loads the JSON config file named name into cfg.  Unknown fields
+// are an error.  Flags given on the command line keep their values.
+func loadConfig(cfg *Config, name string) error {
+	/* Note what was set on the command line, to put back later. */
+	set := make(map[*flag.Flag]string)
+	flag.Visit(func(f *flag.Flag) { set[f] = f.Value.String() })
+
+	/* Load the file. */
+	f, err := os.Open(name)
+	if nil != err {
+		return err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); nil != err {
+		return err
+	}
+
+	/* Command line wins. */
+	for f, v := range set {
+		if err := f.Value.Set(v); nil != err {
+			return fmt.Errorf("restoring -%s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
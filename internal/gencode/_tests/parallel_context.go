@@ -0,0 +1,193 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Cancel everything on SIGINT or SIGTERM. */
+	ctx, stop := signal.NotifyContext(
+		context.Background(),
+		os.Interrupt,
+		syscall.SIGTERM,
+	)
+	defer stop()
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+	}
+
+	/* Start some task executors. */
+	var (
+		ch = make(chan Task)
+		wg sync.WaitGroup
+	)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ctx, ch, &wg, ec)
+	}
+
+	/* Send the tasks to be executed. */
+	tasks, err := getTasks(ctx)
+	if nil != err {
+		log.Fatalf("Error getting tasks: %s", err)
+	}
+	for _, task := range tasks {
+		if nil != ctx.Err() || ec.gaveUp() {
+			break
+		}
+		ch <- task
+	}
+
+	/* Wait for the executors to finish executing. */
+	close(ch)
+	wg.Wait()
+
+	/* All done. */
+	if !*noSummary {
+		/* Note if we didn't quite finish. */
+		how := "Done"
+		if nil != ctx.Err() {
+			how = "Interrupted"
+		}
+		log.Printf(
+			"%s in %s (%d failed).",
+			how,
+			time.Since(ProgramStart).Round(time.Millisecond),
+			NFailed.Load(),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+/* getTasks returns a list of tasks to execute. */
+func getTasks(ctx context.Context) ([]Task, error) {
+	return make([]Task, 0), nil
+}
+
+/* taskExecutor executes the tasks sent on ch. */
+func taskExecutor(
+	ctx context.Context,
+	ch <-chan Task,
+	wg *sync.WaitGroup,
+	ec execConfig,
+) {
+	defer wg.Done()
+	for t := range ch {
+		runTask(ctx, t, ec)
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// true if t was executed successfully.
+func runTask(ctx context.Context, t Task, ec execConfig) bool {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return false
+	}
+
+	/* Don't start anything new if we're stopping. */
+	if nil != ctx.Err() {
+		return false
+	}
+
+	/* Do the thing and note if it didn't work. */
+	err := executeTask(ctx, t)
+	if nil == err {
+		return true
+	}
+	log.Printf("Task failed: %s", err)
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+	}
+	return false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* executeTask executes a single task. */
+func executeTask(ctx context.Context, t Task) error {
+	log.Printf("Executing a task")
+	return nil
+}
@@ -0,0 +1,353 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+
+	/* NTimedOut keeps track of the number of failed tasks which timed
+	out. */
+	NTimedOut atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// Result is the result of executing a Task.
+type Result struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+	/* Closed when maxErrors tasks have failed, to stop reading tasks. */
+	giveUp chan struct{}
+	/* Give each task this long to finish, if nonzero. */
+	timeout time.Duration
+	/* Retry retryable failures this many times. */
+	retries uint
+	/* Wait about this long before the first retry, doubling each time. */
+	backoff time.Duration
+}
+
+// RetryableError wraps an error returned by executeTask to indicate the task
+// may be retried.
+type RetryableError struct{ Err error }
+
+// Error implements the error interface.
+func (err RetryableError) Error() string { return err.Err.Error() }
+
+// Unwrap returns the wrapped error.
+func (err RetryableError) Unwrap() error { return err.Err }
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+		taskTimeout = flag.Duration(
+			"task-timeout",
+			0,
+			"Per-task `timeout` (0 for none)",
+		)
+		retries = flag.Uint(
+			"retries",
+			0,
+			"Retry retryable task failures up to `count` times",
+		)
+		backoff = flag.Duration(
+			"backoff",
+			time.Second,
+			"Initial retry backoff `duration`, doubled each retry",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Cancel everything on SIGINT or SIGTERM. */
+	ctx, stop := signal.NotifyContext(
+		context.Background(),
+		os.Interrupt,
+		syscall.SIGTERM,
+	)
+	defer stop()
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+		giveUp:    make(chan struct{}),
+		timeout:   *taskTimeout,
+		retries:   *retries,
+		backoff:   *backoff,
+	}
+
+	/* Start some task executors and something to write their results. */
+	var (
+		ch      = make(chan Task)
+		results = make(chan Result)
+		wDone   = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	go resultWriter(results, wDone)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ctx, ch, results, &wg, ec)
+	}
+
+	/* Send the tasks to be executed as they're read. */
+	gtErr := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		gtErr <- getTasks(ctx, ch, ec.giveUp)
+	}()
+
+	/* Wait for the executors to finish executing and make sure we got
+	all of the tasks. */
+	wg.Wait()
+	taskErr := <-gtErr
+	if nil != taskErr {
+		log.Printf("Error getting tasks: %s", taskErr)
+	}
+
+	/* Wait for the last of the results to be written. */
+	close(results)
+	<-wDone
+
+	/* All done. */
+	if !*noSummary {
+		/* Note if we didn't quite finish. */
+		how := "Done"
+		if nil != ctx.Err() {
+			how = "Interrupted"
+		}
+		log.Printf(
+			"%s in %s (%d failed, %d timed out).",
+			how,
+			time.Since(ProgramStart).Round(time.Millisecond),
+			NFailed.Load(),
+			NTimedOut.Load(),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if nil != taskErr || 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+// getTasks sends tasks read from stdin to ch.  It returns at the end of stdin,
+// on error, or when ctx is done or giveUp is closed, even if it's still waiting
+// for stdin.
+func getTasks(
+	ctx context.Context,
+	ch chan<- Task,
+	giveUp <-chan struct{},
+) error {
+	/* Read in the background, so we can stop waiting for more to read
+	if we're stopping. */
+	var (
+		tasks = make(chan Task)
+		sErr  = make(chan error, 1)
+		done  = make(chan struct{})
+	)
+	defer close(done)
+	go func() {
+		defer close(tasks)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			/* TODO: Turn scanner.Text() into a Task. */
+			select {
+			case tasks <- Task{}:
+			case <-done:
+				return /* Nobody's listening. */
+			}
+		}
+		sErr <- scanner.Err()
+	}()
+
+	/* Pass on tasks until we run out or are stopped. */
+	for {
+		select {
+		case t, ok := <-tasks:
+			if !ok {
+				return <-sErr
+			}
+			ch <- t
+		case <-ctx.Done():
+			return nil
+		case <-giveUp:
+			return nil
+		}
+	}
+}
+
+/* taskExecutor executes the tasks sent on ch and sends the results. */
+func taskExecutor(
+	ctx context.Context,
+	ch <-chan Task,
+	results chan<- Result,
+	wg *sync.WaitGroup,
+	ec execConfig,
+) {
+	defer wg.Done()
+	for t := range ch {
+		if r, ok := runTask(ctx, t, ec); ok {
+			results <- r
+		}
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// t's result and true if t was executed successfully.
+func runTask(ctx context.Context, t Task, ec execConfig) (Result, bool) {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return Result{}, false
+	}
+
+	/* Don't start anything new if we're stopping. */
+	if nil != ctx.Err() {
+		return Result{}, false
+	}
+
+	/* Do the thing, retrying if it's worth it. */
+	r, err := tryTask(ctx, t, ec)
+	for try := uint(1); try <= ec.retries; try++ {
+		if !errors.As(err, new(RetryableError)) {
+			break
+		}
+		d := ec.backoff << (try - 1)
+		d += rand.N(d/2 + 1)
+		log.Printf(
+			"Task failed, retry %d/%d in %s: %s",
+			try,
+			ec.retries,
+			d,
+			err,
+		)
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+		}
+		if nil != ctx.Err() {
+			break /* No more tries if we're stopping. */
+		}
+		r, err = tryTask(ctx, t, ec)
+	}
+
+	/* Tasks we stopped didn't really fail. */
+	if nil != ctx.Err() && errors.Is(err, ctx.Err()) {
+		return Result{}, false
+	}
+
+	/* Note if it didn't work. */
+	if nil == err {
+		return r, true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Task timed out: %s", err)
+		NTimedOut.Add(1)
+	} else {
+		log.Printf("Task failed: %s", err)
+	}
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+		close(ec.giveUp)
+	}
+	return Result{}, false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* tryTask makes a single attempt at executing t. */
+func tryTask(ctx context.Context, t Task, ec execConfig) (Result, error) {
+	/* Give the task a deadline, if it should have one. */
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if 0 != ec.timeout {
+		ctx, cancel = context.WithTimeout(ctx, ec.timeout)
+		defer cancel()
+	}
+
+	return executeTask(ctx, t)
+}
+
+/* executeTask executes a single task. */
+func executeTask(ctx context.Context, t Task) (Result, error) {
+	log.Printf("Executing a task")
+	return Result{}, nil
+}
+
+// resultWriter writes the results sent on ch to stdout.  It closes done when
+// ch is closed and all results have been written.
+func resultWriter(ch <-chan Result, done chan<- struct{}) {
+	defer close(done)
+	for r := range ch {
+		writeResult(r)
+	}
+}
+
+/* writeResult writes a single result to stdout. */
+func writeResult(r Result) {
+	fmt.Printf("%+v\n", r)
+}
@@ -0,0 +1,232 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+
+	/* NTimedOut keeps track of the number of failed tasks which timed
+	out. */
+	NTimedOut atomic.Uint64
+
+	/* Verbosef wil be a no-op if -verbose isn't given. */
+	Verbosef = log.Printf
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+	/* Give each task this long to finish, if nonzero. */
+	timeout time.Duration
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		verbOn = flag.Bool(
+			"verbose",
+			false,
+			"Enable verbose logging",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+		taskTimeout = flag.Duration(
+			"task-timeout",
+			0,
+			"Per-task `timeout` (0 for none)",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+
+	/* Flags may also be set with environment variables. */
+	envFlags()
+	flag.Parse()
+
+	/* Work out verbose logging. */
+	if !*verbOn {
+		Verbosef = func(string, ...any) {}
+	}
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+		timeout:   *taskTimeout,
+	}
+
+	/* Start some task executors. */
+	var (
+		ch = make(chan Task)
+		wg sync.WaitGroup
+	)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ch, &wg, ec)
+	}
+
+	/* Send the tasks to be executed. */
+	tasks, err := getTasks()
+	if nil != err {
+		log.Fatalf("Error getting tasks: %s", err)
+	}
+	for _, task := range tasks {
+		if ec.gaveUp() {
+			break
+		}
+		ch <- task
+	}
+
+	/* Wait for the executors to finish executing. */
+	close(ch)
+	wg.Wait()
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done in %s (%d failed, %d timed out).",
+			time.Since(ProgramStart).Round(time.Millisecond),
+			NFailed.Load(),
+			NTimedOut.Load(),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+/* getTasks returns a list of tasks to execute. */
+func getTasks() ([]Task, error) {
+	return make([]Task, 0), nil
+}
+
+/* taskExecutor executes the tasks sent on ch. */
+func taskExecutor(ch <-chan Task, wg *sync.WaitGroup, ec execConfig) {
+	defer wg.Done()
+	for t := range ch {
+		runTask(t, ec)
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// true if t was executed successfully.
+func runTask(t Task, ec execConfig) bool {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return false
+	}
+
+	/* Give the task a deadline, if it should have one. */
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if 0 != ec.timeout {
+		ctx, cancel = context.WithTimeout(ctx, ec.timeout)
+		defer cancel()
+	}
+
+	/* Do the thing and note if it didn't work. */
+	err := executeTask(ctx, t)
+	if nil == err {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Task timed out: %s", err)
+		NTimedOut.Add(1)
+	} else {
+		log.Printf("Task failed: %s", err)
+	}
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+	}
+	return false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* executeTask executes a single task. */
+func executeTask(ctx context.Context, t Task) error {
+	log.Printf("Executing a task")
+	return nil
+}
+
+// envFlags sets flags from environment variables named after the flags, in
+// upper case with dashes replaced by underscores and prefixed with
+// COOLTOOL_.  It also adds the variables' names to the flags' usage.
+// It must be called before flag.Parse, so flags given on the command line
+// take precedence.
+func envFlags() {
+	flag.VisitAll(func(f *flag.Flag) {
+		n := "COOLTOOL_" + strings.ToUpper(
+			strings.ReplaceAll(f.Name, "-", "_"),
+		)
+		f.Usage += " [$" + n + "]"
+		v, ok := os.LookupEnv(n)
+		if !ok {
+			return
+		}
+		if err := f.Value.Set(v); nil != err {
+			log.Fatalf("Invalid value %q for $%s: %s", v, n, err)
+		}
+	})
+}
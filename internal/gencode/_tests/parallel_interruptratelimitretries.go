@@ -0,0 +1,330 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// Result is the result of executing a Task.
+type Result struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+	/* Wait for a token before starting each task, if not nil. */
+	tokens <-chan struct{}
+	/* Retry retryable failures this many times. */
+	retries uint
+	/* Wait about this long before the first retry, doubling each time. */
+	backoff time.Duration
+}
+
+// RetryableError wraps an error returned by executeTask to indicate the task
+// may be retried.
+type RetryableError struct{ Err error }
+
+// Error implements the error interface.
+func (err RetryableError) Error() string { return err.Err.Error() }
+
+// Unwrap returns the wrapped error.
+func (err RetryableError) Unwrap() error { return err.Err }
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+		rate = flag.Float64(
+			"rate",
+			0,
+			"Limit to `rate` tasks per second (0 for no limit)",
+		)
+		retries = flag.Uint(
+			"retries",
+			0,
+			"Retry retryable task failures up to `count` times",
+		)
+		backoff = flag.Duration(
+			"backoff",
+			time.Second,
+			"Initial retry backoff `duration`, doubled each retry",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Finish up nicely on the first signal, exit on the second. */
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handleSignals(cancel)
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+		tokens:    startTokenBucket(*rate),
+		retries:   *retries,
+		backoff:   *backoff,
+	}
+
+	/* Start some task executors and something to write their results. */
+	var (
+		ch      = make(chan Task)
+		results = make(chan Result)
+		wDone   = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	go resultWriter(results, wDone)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ctx, ch, results, &wg, ec)
+	}
+
+	/* Send the tasks to be executed. */
+	tasks, err := getTasks(ctx)
+	if nil != err {
+		log.Fatalf("Error getting tasks: %s", err)
+	}
+	for _, task := range tasks {
+		if nil != ctx.Err() || ec.gaveUp() {
+			break
+		}
+		ch <- task
+	}
+
+	/* Wait for the executors to finish executing. */
+	close(ch)
+	wg.Wait()
+
+	/* Wait for the last of the results to be written. */
+	close(results)
+	<-wDone
+
+	/* All done. */
+	if !*noSummary {
+		/* Note if we didn't quite finish. */
+		how := "Done"
+		if nil != ctx.Err() {
+			how = "Interrupted"
+		}
+		log.Printf(
+			"%s in %s (%d failed).",
+			how,
+			time.Since(ProgramStart).Round(time.Millisecond),
+			NFailed.Load(),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+/* getTasks returns a list of tasks to execute. */
+func getTasks(ctx context.Context) ([]Task, error) {
+	return make([]Task, 0), nil
+}
+
+/* taskExecutor executes the tasks sent on ch and sends the results. */
+func taskExecutor(
+	ctx context.Context,
+	ch <-chan Task,
+	results chan<- Result,
+	wg *sync.WaitGroup,
+	ec execConfig,
+) {
+	defer wg.Done()
+	for t := range ch {
+		if r, ok := runTask(ctx, t, ec); ok {
+			results <- r
+		}
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// t's result and true if t was executed successfully.
+func runTask(ctx context.Context, t Task, ec execConfig) (Result, bool) {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return Result{}, false
+	}
+
+	/* Don't start anything new if we're stopping. */
+	if nil != ctx.Err() {
+		return Result{}, false
+	}
+
+	/* Do the thing, retrying if it's worth it. */
+	r, err := tryTask(ctx, t, ec)
+	for try := uint(1); try <= ec.retries; try++ {
+		if !errors.As(err, new(RetryableError)) {
+			break
+		}
+		d := ec.backoff << (try - 1)
+		d += rand.N(d/2 + 1)
+		log.Printf(
+			"Task failed, retry %d/%d in %s: %s",
+			try,
+			ec.retries,
+			d,
+			err,
+		)
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+		}
+		if nil != ctx.Err() {
+			break /* No more tries if we're stopping. */
+		}
+		r, err = tryTask(ctx, t, ec)
+	}
+
+	/* Tasks we stopped didn't really fail. */
+	if nil != ctx.Err() && errors.Is(err, ctx.Err()) {
+		return Result{}, false
+	}
+
+	/* Note if it didn't work. */
+	if nil == err {
+		return r, true
+	}
+	log.Printf("Task failed: %s", err)
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+	}
+	return Result{}, false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* tryTask makes a single attempt at executing t. */
+func tryTask(ctx context.Context, t Task, ec execConfig) (Result, error) {
+	/* Don't go too fast. */
+	if nil != ec.tokens {
+		select {
+		case <-ec.tokens:
+		case <-ctx.Done():
+			return Result{}, ctx.Err()
+		}
+	}
+
+	return executeTask(ctx, t)
+}
+
+/* executeTask executes a single task. */
+func executeTask(ctx context.Context, t Task) (Result, error) {
+	log.Printf("Executing a task")
+	return Result{}, nil
+}
+
+// resultWriter writes the results sent on ch to stdout.  It closes done when
+// ch is closed and all results have been written.
+func resultWriter(ch <-chan Result, done chan<- struct{}) {
+	defer close(done)
+	for r := range ch {
+		writeResult(r)
+	}
+}
+
+/* writeResult writes a single result to stdout. */
+func writeResult(r Result) {
+	fmt.Printf("%+v\n", r)
+}
+
+// startTokenBucket returns a channel which receives perSec tokens per second,
+// holding up to a second's worth.  If perSec isn't positive, startTokenBucket
+// returns nil.  Rates above one token per nanosecond are treated as one token
+// per nanosecond.
+func startTokenBucket(perSec float64) <-chan struct{} {
+	if 0 >= perSec {
+		return nil
+	}
+	perSec = min(perSec, float64(time.Second))
+	var (
+		ch    = make(chan struct{}, max(1, int(perSec)))
+		every = max(time.Duration(float64(time.Second)/perSec), 1)
+	)
+	go func() {
+		for range time.Tick(every) {
+			select {
+			case ch <- struct{}{}:
+			default: /* Bucket's full. */
+			}
+		}
+	}()
+	return ch
+}
+
+// handleSignals calls cancel when SIGINT or SIGTERM is caught and terminates
+// the program if either is caught again.
+func handleSignals(cancel context.CancelFunc) {
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	log.Printf("Caught %s, finishing up; again to exit now", <-ch)
+	cancel()
+	log.Fatalf("Caught %s, exiting", <-ch)
+}
@@ -0,0 +1,297 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NDone keeps track of the number of things we've done. */
+	NDone atomic.Uint64
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct {
+	seq uint64 /* Sequence number, for ordering results. */
+}
+
+// Result is the result of executing a Task.
+type Result struct {
+	seq  uint64 /* Task's sequence number. */
+	skip bool   /* Task failed or was skipped; nothing to write. */
+}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+	/* Closed when maxErrors tasks have failed, to stop reading tasks. */
+	giveUp chan struct{}
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Finish up nicely on the first signal, exit on the second. */
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handleSignals(cancel)
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+		giveUp:    make(chan struct{}),
+	}
+
+	/* Start some task executors and something to write their results. */
+	var (
+		ch      = make(chan Task)
+		results = make(chan Result)
+		wDone   = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	go resultWriter(results, wDone)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ctx, ch, results, &wg, ec)
+	}
+
+	/* Send the tasks to be executed as they're read. */
+	gtErr := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		gtErr <- getTasks(ctx, ch, ec.giveUp)
+	}()
+
+	/* Wait for the executors to finish executing and make sure we got
+	all of the tasks. */
+	wg.Wait()
+	taskErr := <-gtErr
+	if nil != taskErr {
+		log.Printf("Error getting tasks: %s", taskErr)
+	}
+
+	/* Wait for the last of the results to be written. */
+	close(results)
+	<-wDone
+
+	/* All done. */
+	if !*noSummary {
+		/* Note if we didn't quite finish. */
+		how := "Done"
+		if nil != ctx.Err() {
+			how = "Interrupted"
+		}
+		log.Printf(
+			"%s.  Finished %d (%d failed) in %s.",
+			how,
+			NDone.Load(),
+			NFailed.Load(),
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if nil != taskErr || 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+// getTasks sends tasks read from stdin to ch.  It returns at the end of stdin,
+// on error, or when ctx is done or giveUp is closed, even if it's still waiting
+// for stdin.
+func getTasks(
+	ctx context.Context,
+	ch chan<- Task,
+	giveUp <-chan struct{},
+) error {
+	/* Read in the background, so we can stop waiting for more to read
+	if we're stopping. */
+	var (
+		tasks = make(chan Task)
+		sErr  = make(chan error, 1)
+		done  = make(chan struct{})
+	)
+	defer close(done)
+	go func() {
+		defer close(tasks)
+		scanner := bufio.NewScanner(os.Stdin)
+		for n := uint64(0); scanner.Scan(); n++ {
+			/* TODO: Turn scanner.Text() into a Task. */
+			select {
+			case tasks <- Task{seq: n}:
+			case <-done:
+				return /* Nobody's listening. */
+			}
+		}
+		sErr <- scanner.Err()
+	}()
+
+	/* Pass on tasks until we run out or are stopped. */
+	for {
+		select {
+		case t, ok := <-tasks:
+			if !ok {
+				return <-sErr
+			}
+			ch <- t
+		case <-ctx.Done():
+			return nil
+		case <-giveUp:
+			return nil
+		}
+	}
+}
+
+/* taskExecutor executes the tasks sent on ch and sends the results. */
+func taskExecutor(
+	ctx context.Context,
+	ch <-chan Task,
+	results chan<- Result,
+	wg *sync.WaitGroup,
+	ec execConfig,
+) {
+	defer wg.Done()
+	for t := range ch {
+		/* Send something even if the task failed, so the writer
+		doesn't wait for it. */
+		r, ok := runTask(ctx, t, ec)
+		r.seq = t.seq
+		r.skip = !ok
+		results <- r
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// t's result and true if t was executed successfully.
+func runTask(ctx context.Context, t Task, ec execConfig) (Result, bool) {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return Result{}, false
+	}
+
+	/* Don't start anything new if we're stopping. */
+	if nil != ctx.Err() {
+		return Result{}, false
+	}
+
+	/* Do the thing and note if it didn't work. */
+	r, err := executeTask(ctx, t)
+	if nil == err {
+		return r, true
+	}
+	log.Printf("Task failed: %s", err)
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+		close(ec.giveUp)
+	}
+	return Result{}, false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* executeTask executes a single task. */
+func executeTask(ctx context.Context, t Task) (Result, error) {
+	defer NDone.Add(1)
+	log.Printf("Executing a task")
+	return Result{}, nil
+}
+
+// resultWriter writes the results sent on ch to stdout in the order in which
+// their tasks were sent, holding on to results which finish early.  It closes
+// done when ch is closed and all results have been written.
+func resultWriter(ch <-chan Result, done chan<- struct{}) {
+	defer close(done)
+	var (
+		next    uint64
+		pending = make(map[uint64]Result)
+	)
+	for r := range ch {
+		pending[r.seq] = r
+		for r, ok := pending[next]; ok; r, ok = pending[next] {
+			delete(pending, next)
+			if !r.skip {
+				writeResult(r)
+			}
+			next++
+		}
+	}
+}
+
+/* writeResult writes a single result to stdout. */
+func writeResult(r Result) {
+	fmt.Printf("%+v\n", r)
+}
+
+// handleSignals calls cancel when SIGINT or SIGTERM is caught and terminates
+// the program if either is caught again.
+func handleSignals(cancel context.CancelFunc) {
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	log.Printf("Caught %s, finishing up; again to exit now", <-ch)
+	cancel()
+	log.Fatalf("Caught %s, exiting", <-ch)
+}
@@ -0,0 +1,218 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct {
+	seq uint64 /* Sequence number, for ordering results. */
+}
+
+// Result is the result of executing a Task.
+type Result struct {
+	seq  uint64 /* Task's sequence number. */
+	skip bool   /* Task failed or was skipped; nothing to write. */
+}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+	}
+
+	/* Start some task executors and something to write their results. */
+	var (
+		ch      = make(chan Task)
+		results = make(chan Result)
+		wDone   = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	go resultWriter(results, wDone)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ch, results, &wg, ec)
+	}
+
+	/* Send the tasks to be executed. */
+	tasks, err := getTasks()
+	if nil != err {
+		log.Fatalf("Error getting tasks: %s", err)
+	}
+	for i, task := range tasks {
+		if ec.gaveUp() {
+			break
+		}
+		task.seq = uint64(i)
+		ch <- task
+	}
+
+	/* Wait for the executors to finish executing. */
+	close(ch)
+	wg.Wait()
+
+	/* Wait for the last of the results to be written. */
+	close(results)
+	<-wDone
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done in %s (%d failed).",
+			time.Since(ProgramStart).Round(time.Millisecond),
+			NFailed.Load(),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+/* getTasks returns a list of tasks to execute. */
+func getTasks() ([]Task, error) {
+	return make([]Task, 0), nil
+}
+
+/* taskExecutor executes the tasks sent on ch and sends the results. */
+func taskExecutor(
+	ch <-chan Task,
+	results chan<- Result,
+	wg *sync.WaitGroup,
+	ec execConfig,
+) {
+	defer wg.Done()
+	for t := range ch {
+		/* Send something even if the task failed, so the writer
+		doesn't wait for it. */
+		r, ok := runTask(t, ec)
+		r.seq = t.seq
+		r.skip = !ok
+		results <- r
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// t's result and true if t was executed successfully.
+func runTask(t Task, ec execConfig) (Result, bool) {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return Result{}, false
+	}
+
+	/* Do the thing and note if it didn't work. */
+	r, err := executeTask(t)
+	if nil == err {
+		return r, true
+	}
+	log.Printf("Task failed: %s", err)
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+	}
+	return Result{}, false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* executeTask executes a single task. */
+func executeTask(t Task) (Result, error) {
+	log.Printf("Executing a task")
+	return Result{}, nil
+}
+
+// resultWriter writes the results sent on ch to stdout in the order in which
+// their tasks were sent, holding on to results which finish early.  It closes
+// done when ch is closed and all results have been written.
+func resultWriter(ch <-chan Result, done chan<- struct{}) {
+	defer close(done)
+	var (
+		next    uint64
+		pending = make(map[uint64]Result)
+	)
+	for r := range ch {
+		pending[r.seq] = r
+		for r, ok := pending[next]; ok; r, ok = pending[next] {
+			delete(pending, next)
+			if !r.skip {
+				writeResult(r)
+			}
+			next++
+		}
+	}
+}
+
+/* writeResult writes a single result to stdout. */
+func writeResult(r Result) {
+	fmt.Printf("%+v\n", r)
+}
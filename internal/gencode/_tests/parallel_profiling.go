@@ -0,0 +1,294 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"expvar"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NDone keeps track of the number of things we've done. */
+	NDone atomic.Uint64
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		cpuProfile = flag.String(
+			"cpuprofile",
+			"",
+			"Write a CPU profile to `file`",
+		)
+		memProfile = flag.String(
+			"memprofile",
+			"",
+			"Write a memory profile to `file` before exiting",
+		)
+		traceFile = flag.String(
+			"trace",
+			"",
+			"Write an execution trace to `file`",
+		)
+		debugListen = flag.String(
+			"debug-listen",
+			"",
+			"Serve pprof and expvar on `address`",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Profile and trace, if we're meant to. */
+	stopProfiling := startProfiling(
+		*cpuProfile,
+		*memProfile,
+		*traceFile,
+		*debugListen,
+	)
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+	}
+
+	/* Start some task executors. */
+	var (
+		ch = make(chan Task)
+		wg sync.WaitGroup
+	)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ch, &wg, ec)
+	}
+
+	/* Send the tasks to be executed. */
+	tasks, err := getTasks()
+	if nil != err {
+		log.Fatalf("Error getting tasks: %s", err)
+	}
+	for _, task := range tasks {
+		if ec.gaveUp() {
+			break
+		}
+		ch <- task
+	}
+
+	/* Wait for the executors to finish executing. */
+	close(ch)
+	wg.Wait()
+
+	/* Finish profiling and tracing. */
+	stopProfiling()
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done.  Finished %d (%d failed) in %s.",
+			NDone.Load(),
+			NFailed.Load(),
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+/* getTasks returns a list of tasks to execute. */
+func getTasks() ([]Task, error) {
+	return make([]Task, 0), nil
+}
+
+/* taskExecutor executes the tasks sent on ch. */
+func taskExecutor(ch <-chan Task, wg *sync.WaitGroup, ec execConfig) {
+	defer wg.Done()
+	for t := range ch {
+		runTask(t, ec)
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// true if t was executed successfully.
+func runTask(t Task, ec execConfig) bool {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return false
+	}
+
+	/* Do the thing and note if it didn't work. */
+	err := executeTask(t)
+	if nil == err {
+		return true
+	}
+	log.Printf("Task failed: %s", err)
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+	}
+	return false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* executeTask executes a single task. */
+func executeTask(t Task) error {
+	defer NDone.Add(1)
+	log.Printf("Executing a task")
+	return nil
+}
+
+// startProfiling starts CPU profiling and execution tracing to the named files
+// and serves net/http/pprof and expvar on debugAddr, each only if the name or
+// address isn't empty.  The returned function stops profiling and tracing and
+// writes a memory profile to memFile, if it isn't empty.
+func startProfiling(cpuFile, memFile, traceFile, debugAddr string) func() {
+	var stops []func()
+
+	/* Profile the CPU. */
+	if "" != cpuFile {
+		f, err := os.Create(cpuFile)
+		if nil != err {
+			log.Fatalf("Error creating CPU profile: %s", err)
+		}
+		if err := pprof.StartCPUProfile(f); nil != err {
+			log.Fatalf("Error starting CPU profile: %s", err)
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			if err := f.Close(); nil != err {
+				log.Printf("Error closing CPU profile: %s", err)
+			}
+		})
+	}
+
+	/* Trace execution. */
+	if "" != traceFile {
+		f, err := os.Create(traceFile)
+		if nil != err {
+			log.Fatalf("Error creating trace file: %s", err)
+		}
+		if err := trace.Start(f); nil != err {
+			log.Fatalf("Error starting trace: %s", err)
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			if err := f.Close(); nil != err {
+				log.Printf("Error closing trace file: %s", err)
+			}
+		})
+	}
+
+	/* Profile memory, once we're done. */
+	if "" != memFile {
+		stops = append(stops, func() {
+			f, err := os.Create(memFile)
+			if nil != err {
+				log.Printf(
+					"Error creating memory profile: %s",
+					err,
+				)
+				return
+			}
+			defer f.Close()
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(f); nil != err {
+				log.Printf(
+					"Error writing memory profile: %s",
+					err,
+				)
+			}
+		})
+	}
+
+	/* Let the debug server tell how much we've done. */
+	expvar.Publish("ndone", expvar.Func(func() any { return NDone.Load() }))
+
+	/* Serve pprof and expvar. */
+	if "" != debugAddr {
+		l, err := net.Listen("tcp", debugAddr)
+		if nil != err {
+			log.Fatalf("Error listening on %s: %s", debugAddr, err)
+		}
+		log.Printf("Serving pprof and expvar on %s", l.Addr())
+		go func() {
+			log.Printf("Debug server died: %s", http.Serve(l, nil))
+		}()
+	}
+
+	return func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
+}
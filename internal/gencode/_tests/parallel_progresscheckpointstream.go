@@ -0,0 +1,370 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NDone keeps track of the number of things we've done. */
+	NDone atomic.Uint64
+
+	/* NTotal is the total number of things to do, if known. */
+	NTotal atomic.Uint64
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+
+	/* NSkipped keeps track of the number of tasks skipped because they
+	finished in a previous run. */
+	NSkipped atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+	/* Closed when maxErrors tasks have failed, to stop reading tasks. */
+	giveUp chan struct{}
+	/* Skip tasks which finished in previous runs, if not nil. */
+	cp *checkpoint
+}
+
+// checkpoint keeps track of finished tasks in a file, so they can be skipped
+// in later runs.  A nil checkpoint keeps track of nothing.
+type checkpoint struct {
+	prev map[string]struct{} /* Keys of tasks from previous runs. */
+	mu   sync.Mutex
+	f    *os.File
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		progress = flag.Duration(
+			"progress",
+			0,
+			"Log progress every `interval` (0 for never)",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+		stateFile = flag.String(
+			"state",
+			"",
+			"Note finished tasks in `file` and skip them next run",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Report progress every so often, if we're meant to. */
+	stopProgress := startProgress(*progress)
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+		giveUp:    make(chan struct{}),
+	}
+
+	/* Skip tasks which are already done, if we're keeping track. */
+	if "" != *stateFile {
+		var err error
+		if ec.cp, err = openCheckpoint(*stateFile); nil != err {
+			log.Fatalf("Error opening checkpoint file: %s", err)
+		}
+	}
+
+	/* Start some task executors. */
+	var (
+		ch = make(chan Task)
+		wg sync.WaitGroup
+	)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ch, &wg, ec)
+	}
+
+	/* Send the tasks to be executed as they're read. */
+	gtErr := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		gtErr <- getTasks(ch, ec.giveUp)
+	}()
+
+	/* Wait for the executors to finish executing and make sure we got
+	all of the tasks. */
+	wg.Wait()
+	taskErr := <-gtErr
+	if nil != taskErr {
+		log.Printf("Error getting tasks: %s", taskErr)
+	}
+
+	/* Make sure all the finished tasks are noted. */
+	if err := ec.cp.close(); nil != err {
+		log.Printf("Error closing checkpoint file: %s", err)
+	}
+
+	/* No more progress reports. */
+	stopProgress()
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done.  Finished %d (%d failed, %d skipped) in %s.",
+			NDone.Load(),
+			NFailed.Load(),
+			NSkipped.Load(),
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if nil != taskErr || 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+// getTasks sends tasks read from stdin to ch.  It returns at the end of stdin,
+// on error, or when giveUp is closed.
+func getTasks(ch chan<- Task, giveUp <-chan struct{}) error {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		/* TODO: Turn scanner.Text() into a Task. */
+		select {
+		case ch <- Task{}:
+		case <-giveUp:
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// Key returns a string which identifies t between runs, for checkpointing.
+// Tasks with an empty key are never skipped.  Keys may not contain newlines.
+func (t Task) Key() string {
+	/* TODO: Work out something which uniquely identifies t. */
+	return ""
+}
+
+/* taskExecutor executes the tasks sent on ch. */
+func taskExecutor(ch <-chan Task, wg *sync.WaitGroup, ec execConfig) {
+	defer wg.Done()
+	for t := range ch {
+		runTask(t, ec)
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// true if t was executed successfully.
+func runTask(t Task, ec execConfig) bool {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return false
+	}
+
+	/* Don't redo what's already done. */
+	key := t.Key()
+	if ec.cp.finishedBefore(key) {
+		NSkipped.Add(1)
+		return false
+	}
+
+	/* Do the thing and note if it didn't work. */
+	err := executeTask(t)
+	if nil == err {
+		if err := ec.cp.finished(key); nil != err {
+			log.Printf("Error checkpointing task %q: %s", key, err)
+		}
+		return true
+	}
+	log.Printf("Task failed: %s", err)
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+		close(ec.giveUp)
+	}
+	return false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* executeTask executes a single task. */
+func executeTask(t Task) error {
+	defer NDone.Add(1)
+	log.Printf("Executing a task")
+	return nil
+}
+
+// openCheckpoint reads the keys of tasks finished in previous runs from the
+// named file, which may not exist, and opens it for noting more.
+func openCheckpoint(name string) (*checkpoint, error) {
+	cp := &checkpoint{prev: make(map[string]struct{})}
+
+	/* Get the keys from last time. */
+	f, err := os.Open(name)
+	if nil == err {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			cp.prev[scanner.Text()] = struct{}{}
+		}
+		if err := scanner.Err(); nil != err {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	/* Open the file for this time's keys. */
+	if cp.f, err = os.OpenFile(
+		name,
+		os.O_WRONLY|os.O_APPEND|os.O_CREATE,
+		0600,
+	); nil != err {
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+/* finishedBefore returns true if key finished in a previous run. */
+func (cp *checkpoint) finishedBefore(key string) bool {
+	if nil == cp || "" == key {
+		return false
+	}
+	_, ok := cp.prev[key]
+	return ok
+}
+
+/* finished notes that the task with the given key has finished. */
+func (cp *checkpoint) finished(key string) error {
+	if nil == cp || "" == key {
+		return nil
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	_, err := fmt.Fprintln(cp.f, key)
+	return err
+}
+
+/* close closes cp's underlying file. */
+func (cp *checkpoint) close() error {
+	if nil == cp {
+		return nil
+	}
+	return cp.f.Close()
+}
+
+// startProgress logs progress every interval until the returned function is
+// called.  If interval isn't positive, startProgress does nothing.
+func startProgress(interval time.Duration) (stop func()) {
+	if 0 >= interval {
+		return func() {}
+	}
+	var (
+		ticker  = time.NewTicker(interval)
+		done    = make(chan struct{})
+		stopped = make(chan struct{})
+	)
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				logProgress()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+	}
+}
+
+/* logProgress logs how much we've done and, if we know, how much is left. */
+func logProgress() {
+	var (
+		done  = NDone.Load()
+		total = NTotal.Load()
+		rate  = float64(done) / time.Since(ProgramStart).Seconds()
+	)
+
+	/* If we don't know how much there is to do, life's easy. */
+	if 0 == total {
+		log.Printf("Progress: %d done (%.2f/s)", done, rate)
+		return
+	}
+
+	/* Work out how much longer we've got. */
+	eta := "unknown"
+	if 0 != done && done < total {
+		eta = time.Duration(
+			float64(total-done) / rate * float64(time.Second),
+		).Round(time.Second).String()
+	}
+	log.Printf(
+		"Progress: %d/%d done (%.2f/s), ETA %s",
+		done,
+		total,
+		rate,
+		eta,
+	)
+}
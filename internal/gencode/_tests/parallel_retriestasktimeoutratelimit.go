@@ -0,0 +1,288 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NDone keeps track of the number of things we've done. */
+	NDone atomic.Uint64
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+
+	/* NTimedOut keeps track of the number of failed tasks which timed
+	out. */
+	NTimedOut atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+	/* Wait for a token before starting each task, if not nil. */
+	tokens <-chan struct{}
+	/* Give each task this long to finish, if nonzero. */
+	timeout time.Duration
+	/* Retry retryable failures this many times. */
+	retries uint
+	/* Wait about this long before the first retry, doubling each time. */
+	backoff time.Duration
+}
+
+// RetryableError wraps an error returned by executeTask to indicate the task
+// may be retried.
+type RetryableError struct{ Err error }
+
+// Error implements the error interface.
+func (err RetryableError) Error() string { return err.Err.Error() }
+
+// Unwrap returns the wrapped error.
+func (err RetryableError) Unwrap() error { return err.Err }
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+		rate = flag.Float64(
+			"rate",
+			0,
+			"Limit to `rate` tasks per second (0 for no limit)",
+		)
+		taskTimeout = flag.Duration(
+			"task-timeout",
+			0,
+			"Per-task `timeout` (0 for none)",
+		)
+		retries = flag.Uint(
+			"retries",
+			0,
+			"Retry retryable task failures up to `count` times",
+		)
+		backoff = flag.Duration(
+			"backoff",
+			time.Second,
+			"Initial retry backoff `duration`, doubled each retry",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+		tokens:    startTokenBucket(*rate),
+		timeout:   *taskTimeout,
+		retries:   *retries,
+		backoff:   *backoff,
+	}
+
+	/* Start some task executors. */
+	var (
+		ch = make(chan Task)
+		wg sync.WaitGroup
+	)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ch, &wg, ec)
+	}
+
+	/* Send the tasks to be executed. */
+	tasks, err := getTasks()
+	if nil != err {
+		log.Fatalf("Error getting tasks: %s", err)
+	}
+	for _, task := range tasks {
+		if ec.gaveUp() {
+			break
+		}
+		ch <- task
+	}
+
+	/* Wait for the executors to finish executing. */
+	close(ch)
+	wg.Wait()
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done.  Finished %d (%d failed, %d timed out) in %s.",
+			NDone.Load(),
+			NFailed.Load(),
+			NTimedOut.Load(),
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+/* getTasks returns a list of tasks to execute. */
+func getTasks() ([]Task, error) {
+	return make([]Task, 0), nil
+}
+
+/* taskExecutor executes the tasks sent on ch. */
+func taskExecutor(ch <-chan Task, wg *sync.WaitGroup, ec execConfig) {
+	defer wg.Done()
+	for t := range ch {
+		runTask(t, ec)
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// true if t was executed successfully.
+func runTask(t Task, ec execConfig) bool {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return false
+	}
+
+	/* Count tasks, not tries. */
+	defer NDone.Add(1)
+
+	/* Do the thing, retrying if it's worth it. */
+	err := tryTask(t, ec)
+	for try := uint(1); try <= ec.retries; try++ {
+		if !errors.As(err, new(RetryableError)) {
+			break
+		}
+		d := ec.backoff << (try - 1)
+		d += rand.N(d/2 + 1)
+		log.Printf(
+			"Task failed, retry %d/%d in %s: %s",
+			try,
+			ec.retries,
+			d,
+			err,
+		)
+		time.Sleep(d)
+		err = tryTask(t, ec)
+	}
+
+	/* Note if it didn't work. */
+	if nil == err {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Task timed out: %s", err)
+		NTimedOut.Add(1)
+	} else {
+		log.Printf("Task failed: %s", err)
+	}
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+	}
+	return false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* tryTask makes a single attempt at executing t. */
+func tryTask(t Task, ec execConfig) error {
+	/* Don't go too fast. */
+	if nil != ec.tokens {
+		<-ec.tokens
+	}
+
+	/* Give the task a deadline, if it should have one. */
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if 0 != ec.timeout {
+		ctx, cancel = context.WithTimeout(ctx, ec.timeout)
+		defer cancel()
+	}
+
+	return executeTask(ctx, t)
+}
+
+/* executeTask executes a single task. */
+func executeTask(ctx context.Context, t Task) error {
+	log.Printf("Executing a task")
+	return nil
+}
+
+// startTokenBucket returns a channel which receives perSec tokens per second,
+// holding up to a second's worth.  If perSec isn't positive, startTokenBucket
+// returns nil.  Rates above one token per nanosecond are treated as one token
+// per nanosecond.
+func startTokenBucket(perSec float64) <-chan struct{} {
+	if 0 >= perSec {
+		return nil
+	}
+	perSec = min(perSec, float64(time.Second))
+	var (
+		ch    = make(chan struct{}, max(1, int(perSec)))
+		every = max(time.Duration(float64(time.Second)/perSec), 1)
+	)
+	go func() {
+		for range time.Tick(every) {
+			select {
+			case ch <- struct{}{}:
+			default: /* Bucket's full. */
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,256 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NDone keeps track of the number of things we've done. */
+	NDone atomic.Uint64
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+
+	/* NTimedOut keeps track of the number of failed tasks which timed
+	out. */
+	NTimedOut atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+	/* Give each task this long to finish, if nonzero. */
+	timeout time.Duration
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		logFormat = flag.String(
+			"log-format",
+			"text",
+			"Log `format` (text or json)",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+		taskTimeout = flag.Duration(
+			"task-timeout",
+			0,
+			"Per-task `timeout` (0 for none)",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Set up structured logging. */
+	lo := &slog.HandlerOptions{}
+	var lh slog.Handler
+	switch *logFormat {
+	case "text":
+		lh = slog.NewTextHandler(os.Stderr, lo)
+	case "json":
+		lh = slog.NewJSONHandler(os.Stderr, lo)
+	default:
+		log.Fatalf("Unknown log format %q", *logFormat)
+	}
+	slog.SetDefault(slog.New(lh))
+
+	/* Finish up nicely on the first signal, exit on the second. */
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handleSignals(cancel)
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+		timeout:   *taskTimeout,
+	}
+
+	/* Start some task executors. */
+	var (
+		ch = make(chan Task)
+		wg sync.WaitGroup
+	)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ctx, ch, &wg, ec)
+	}
+
+	/* Send the tasks to be executed. */
+	tasks, err := getTasks(ctx)
+	if nil != err {
+		slog.Error("Error getting tasks", "err", err)
+		os.Exit(1)
+	}
+	for _, task := range tasks {
+		if nil != ctx.Err() || ec.gaveUp() {
+			break
+		}
+		ch <- task
+	}
+
+	/* Wait for the executors to finish executing. */
+	close(ch)
+	wg.Wait()
+
+	/* All done. */
+	if !*noSummary {
+		/* Note if we didn't quite finish. */
+		how := "Done"
+		if nil != ctx.Err() {
+			how = "Interrupted"
+		}
+		slog.Info(
+			how,
+			"elapsed",
+			time.Since(ProgramStart).Round(time.Millisecond),
+			"done", NDone.Load(),
+			"failed", NFailed.Load(),
+			"timed_out", NTimedOut.Load(),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+/* getTasks returns a list of tasks to execute. */
+func getTasks(ctx context.Context) ([]Task, error) {
+	return make([]Task, 0), nil
+}
+
+/* taskExecutor executes the tasks sent on ch. */
+func taskExecutor(
+	ctx context.Context,
+	ch <-chan Task,
+	wg *sync.WaitGroup,
+	ec execConfig,
+) {
+	defer wg.Done()
+	for t := range ch {
+		runTask(ctx, t, ec)
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// true if t was executed successfully.
+func runTask(ctx context.Context, t Task, ec execConfig) bool {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return false
+	}
+
+	/* Don't start anything new if we're stopping. */
+	if nil != ctx.Err() {
+		return false
+	}
+
+	/* Give the task a deadline, if it should have one. */
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if 0 != ec.timeout {
+		ctx, cancel = context.WithTimeout(ctx, ec.timeout)
+		defer cancel()
+	}
+
+	/* Do the thing and note if it didn't work. */
+	err := executeTask(ctx, t)
+	if nil == err {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		slog.Error("Task timed out", "err", err)
+		NTimedOut.Add(1)
+	} else {
+		slog.Error("Task failed", "err", err)
+	}
+	if NFailed.Add(1) == ec.maxErrors {
+		slog.Warn(
+			"Too many failures, skipping remaining tasks",
+			"max_errors", ec.maxErrors,
+		)
+	}
+	return false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* executeTask executes a single task. */
+func executeTask(ctx context.Context, t Task) error {
+	defer NDone.Add(1)
+	slog.Info("Executing a task")
+	return nil
+}
+
+// handleSignals calls cancel when SIGINT or SIGTERM is caught and terminates
+// the program if either is caught again.
+func handleSignals(cancel context.CancelFunc) {
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	slog.Warn(
+		"Caught signal, finishing up; again to exit now",
+		"signal", <-ch,
+	)
+	cancel()
+	slog.Error("Caught signal, exiting", "signal", <-ch)
+	os.Exit(1)
+}
@@ -0,0 +1,367 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
+
+	/* NSkipped keeps track of the number of tasks skipped because they
+	finished in a previous run. */
+	NSkipped atomic.Uint64
+)
+
+// Task contains the information necessary to accomplish a task.
+type Task struct{}
+
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+	/* Closed when maxErrors tasks have failed, to stop reading tasks. */
+	giveUp chan struct{}
+	/* Retry retryable failures this many times. */
+	retries uint
+	/* Wait about this long before the first retry, doubling each time. */
+	backoff time.Duration
+	/* Skip tasks which finished in previous runs, if not nil. */
+	cp *checkpoint
+}
+
+// RetryableError wraps an error returned by executeTask to indicate the task
+// may be retried.
+type RetryableError struct{ Err error }
+
+// Error implements the error interface.
+func (err RetryableError) Error() string { return err.Err.Error() }
+
+// Unwrap returns the wrapped error.
+func (err RetryableError) Unwrap() error { return err.Err }
+
+// checkpoint keeps track of finished tasks in a file, so they can be skipped
+// in later runs.  A nil checkpoint keeps track of nothing.
+type checkpoint struct {
+	prev map[string]struct{} /* Keys of tasks from previous runs. */
+	mu   sync.Mutex
+	f    *os.File
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		logFormat = flag.String(
+			"log-format",
+			"text",
+			"Log `format` (text or json)",
+		)
+		nPar = flag.Uint(
+			"parallel",
+			10,
+			"Parallel task execution `count`",
+		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
+		retries = flag.Uint(
+			"retries",
+			0,
+			"Retry retryable task failures up to `count` times",
+		)
+		backoff = flag.Duration(
+			"backoff",
+			time.Second,
+			"Initial retry backoff `duration`, doubled each retry",
+		)
+		stateFile = flag.String(
+			"state",
+			"",
+			"Note finished tasks in `file` and skip them next run",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Set up structured logging. */
+	lo := &slog.HandlerOptions{}
+	var lh slog.Handler
+	switch *logFormat {
+	case "text":
+		lh = slog.NewTextHandler(os.Stderr, lo)
+	case "json":
+		lh = slog.NewJSONHandler(os.Stderr, lo)
+	default:
+		log.Fatalf("Unknown log format %q", *logFormat)
+	}
+	slog.SetDefault(slog.New(lh))
+
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+		giveUp:    make(chan struct{}),
+		retries:   *retries,
+		backoff:   *backoff,
+	}
+
+	/* Skip tasks which are already done, if we're keeping track. */
+	if "" != *stateFile {
+		var err error
+		if ec.cp, err = openCheckpoint(*stateFile); nil != err {
+			slog.Error("Error opening checkpoint file", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	/* Start some task executors. */
+	var (
+		ch = make(chan Task)
+		wg sync.WaitGroup
+	)
+	for i := uint(0); i < *nPar; i++ {
+		wg.Add(1)
+		go taskExecutor(ch, &wg, ec)
+	}
+
+	/* Send the tasks to be executed as they're read. */
+	gtErr := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		gtErr <- getTasks(ch, ec.giveUp)
+	}()
+
+	/* Wait for the executors to finish executing and make sure we got
+	all of the tasks. */
+	wg.Wait()
+	taskErr := <-gtErr
+	if nil != taskErr {
+		slog.Error("Error getting tasks", "err", taskErr)
+	}
+
+	/* Make sure all the finished tasks are noted. */
+	if err := ec.cp.close(); nil != err {
+		slog.Error("Error closing checkpoint file", "err", err)
+	}
+
+	/* All done. */
+	if !*noSummary {
+		slog.Info(
+			"Done",
+			"elapsed",
+			time.Since(ProgramStart).Round(time.Millisecond),
+			"failed", NFailed.Load(),
+			"skipped", NSkipped.Load(),
+		)
+	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if nil != taskErr || 0 != NFailed.Load() {
+		os.Exit(1)
+	}
+}
+
+// getTasks sends tasks read from stdin to ch.  It returns at the end of stdin,
+// on error, or when giveUp is closed.
+func getTasks(ch chan<- Task, giveUp <-chan struct{}) error {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		/* TODO: Turn scanner.Text() into a Task. */
+		select {
+		case ch <- Task{}:
+		case <-giveUp:
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// Key returns a string which identifies t between runs, for checkpointing.
+// Tasks with an empty key are never skipped.  Keys may not contain newlines.
+func (t Task) Key() string {
+	/* TODO: Work out something which uniquely identifies t. */
+	return ""
+}
+
+/* taskExecutor executes the tasks sent on ch. */
+func taskExecutor(ch <-chan Task, wg *sync.WaitGroup, ec execConfig) {
+	defer wg.Done()
+	for t := range ch {
+		runTask(t, ec)
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// true if t was executed successfully.
+func runTask(t Task, ec execConfig) bool {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return false
+	}
+
+	/* Don't redo what's already done. */
+	key := t.Key()
+	if ec.cp.finishedBefore(key) {
+		NSkipped.Add(1)
+		return false
+	}
+
+	/* Do the thing, retrying if it's worth it. */
+	err := tryTask(t, ec)
+	for try := uint(1); try <= ec.retries; try++ {
+		if !errors.As(err, new(RetryableError)) {
+			break
+		}
+		d := ec.backoff << (try - 1)
+		d += rand.N(d/2 + 1)
+		slog.Warn(
+			"Task failed, retrying",
+			"try", try,
+			"retries", ec.retries,
+			"backoff", d,
+			"err", err,
+		)
+		time.Sleep(d)
+		err = tryTask(t, ec)
+	}
+
+	/* Note if it didn't work. */
+	if nil == err {
+		if err := ec.cp.finished(key); nil != err {
+			slog.Error(
+				"Error checkpointing task",
+				"key", key,
+				"err", err,
+			)
+		}
+		return true
+	}
+	slog.Error("Task failed", "err", err)
+	if NFailed.Add(1) == ec.maxErrors {
+		slog.Warn(
+			"Too many failures, skipping remaining tasks",
+			"max_errors", ec.maxErrors,
+		)
+		close(ec.giveUp)
+	}
+	return false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
+}
+
+/* tryTask makes a single attempt at executing t. */
+func tryTask(t Task, ec execConfig) error {
+	return executeTask(t)
+}
+
+/* executeTask executes a single task. */
+func executeTask(t Task) error {
+	slog.Info("Executing a task")
+	return nil
+}
+
+// openCheckpoint reads the keys of tasks finished in previous runs from the
+// named file, which may not exist, and opens it for noting more.
+func openCheckpoint(name string) (*checkpoint, error) {
+	cp := &checkpoint{prev: make(map[string]struct{})}
+
+	/* Get the keys from last time. */
+	f, err := os.Open(name)
+	if nil == err {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			cp.prev[scanner.Text()] = struct{}{}
+		}
+		if err := scanner.Err(); nil != err {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	/* Open the file for this time's keys. */
+	if cp.f, err = os.OpenFile(
+		name,
+		os.O_WRONLY|os.O_APPEND|os.O_CREATE,
+		0600,
+	); nil != err {
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+/* finishedBefore returns true if key finished in a previous run. */
+func (cp *checkpoint) finishedBefore(key string) bool {
+	if nil == cp || "" == key {
+		return false
+	}
+	_, ok := cp.prev[key]
+	return ok
+}
+
+/* finished notes that the task with the given key has finished. */
+func (cp *checkpoint) finished(key string) error {
+	if nil == cp || "" == key {
+		return nil
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	_, err := fmt.Fprintln(cp.f, key)
+	return err
+}
+
+/* close closes cp's underlying file. */
+func (cp *checkpoint) close() error {
+	if nil == cp {
+		return nil
+	}
+	return cp.f.Close()
+}
@@ -26,11 +26,20 @@ var (
 
 	/* NDone keeps track of the number of things we've done. */
 	NDone atomic.Uint64
+
+	/* NFailed keeps track of the number of tasks which failed. */
+	NFailed atomic.Uint64
 )
 
 // Task contains the information necessary to accomplish a task.
 type Task struct{}
 
+// execConfig controls how tasks are executed.
+type execConfig struct {
+	/* Skip remaining tasks after this many failures, if nonzero. */
+	maxErrors uint64
+}
+
 func main() {
 	/* Command-line flags. */
 	var (
@@ -44,6 +53,11 @@ func main() {
 			10,
 			"Parallel task execution `count`",
 		)
+		maxErrors = flag.Uint64(
+			"max-errors",
+			0,
+			"Give up after `count` failed tasks (0 for no limit)",
+		)
 	)
 	flag.Usage = func() {
 		fmt.Fprintf(
@@ -60,6 +74,11 @@ Options:
 	}
 	flag.Parse()
 
+	/* Work out how tasks should be executed. */
+	ec := execConfig{
+		maxErrors: *maxErrors,
+	}
+
 	/* Start some task executors. */
 	var (
 		ch = make(chan Task)
@@ -67,7 +86,7 @@ Options:
 	)
 	for i := uint(0); i < *nPar; i++ {
 		wg.Add(1)
-		go taskExecutor(ch, &wg)
+		go taskExecutor(ch, &wg, ec)
 	}
 
 	/* Send the tasks to be executed. */
@@ -76,6 +95,9 @@ Options:
 		log.Fatalf("Error getting tasks: %s", err)
 	}
 	for _, task := range tasks {
+		if ec.gaveUp() {
+			break
+		}
 		ch <- task
 	}
 
@@ -86,11 +108,17 @@ Options:
 	/* All done. */
 	if !*noSummary {
 		log.Printf(
-			"Done.  Finished %d in %s.",
+			"Done.  Finished %d (%d failed) in %s.",
 			NDone.Load(),
+			NFailed.Load(),
 			time.Since(ProgramStart).Round(time.Millisecond),
 		)
 	}
+
+	/* Don't pretend everything's fine if it wasn't. */
+	if 0 != NFailed.Load() {
+		os.Exit(1)
+	}
 }
 
 /* getTasks returns a list of tasks to execute. */
@@ -99,15 +127,45 @@ func getTasks() ([]Task, error) {
 }
 
 /* taskExecutor executes the tasks sent on ch. */
-func taskExecutor(ch <-chan Task, wg *sync.WaitGroup) {
+func taskExecutor(ch <-chan Task, wg *sync.WaitGroup, ec execConfig) {
 	defer wg.Done()
 	for t := range ch {
-		executeTask(t)
+		runTask(t, ec)
+	}
+}
+
+// runTask executes t and notes if it failed.  If ec.maxErrors is nonzero and
+// at least that many tasks have already failed, t is skipped.  runTask returns
+// true if t was executed successfully.
+func runTask(t Task, ec execConfig) bool {
+	/* Don't bother if too many tasks have failed. */
+	if ec.gaveUp() {
+		return false
 	}
+
+	/* Do the thing and note if it didn't work. */
+	err := executeTask(t)
+	if nil == err {
+		return true
+	}
+	log.Printf("Task failed: %s", err)
+	if NFailed.Add(1) == ec.maxErrors {
+		log.Printf(
+			"Reached %d failures, skipping remaining tasks",
+			ec.maxErrors,
+		)
+	}
+	return false
+}
+
+// gaveUp returns true if ec.maxErrors tasks have failed.
+func (ec execConfig) gaveUp() bool {
+	return 0 != ec.maxErrors && NFailed.Load() >= ec.maxErrors
 }
 
 /* executeTask executes a single task. */
-func executeTask(t Task) {
+func executeTask(t Task) error {
 	defer NDone.Add(1)
 	log.Printf("Executing a task")
+	return nil
 }
@@ -0,0 +1,224 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+)
+
+// Config holds settings which may be loaded from a JSON file with -config.
+// Its fields point to the corresponding flags' values.
+type Config struct {
+	/* Don't print a summary on exit. */
+	NoSummary *bool `json:"no-summary"`
+	/* Run interval. */
+	Interval *jsonDuration `json:"interval"`
+	/* Maximum random delay added to each run. */
+	Jitter *jsonDuration `json:"jitter"`
+	/* Number of runs to make, or 0 for no limit. */
+	Count *uint `json:"count"`
+}
+
+// jsonDuration is a time.Duration which is represented in JSON as a string
+// understood by time.ParseDuration.
+type jsonDuration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *jsonDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); nil != err {
+		return err
+	}
+	pd, err := time.ParseDuration(s)
+	if nil != err {
+		return err
+	}
+	*d = jsonDuration(pd)
+	return nil
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		configFile = flag.String(
+			"config",
+			"",
+			"Load settings from JSON `file`",
+		)
+		printCfg = flag.Bool(
+			"print-config",
+			false,
+			"Print the effective settings as JSON and exit",
+		)
+		interval = flag.Duration(
+			"interval",
+			time.Minute,
+			"Run `interval`",
+		)
+		jitter = flag.Duration(
+			"jitter",
+			0,
+			"Maximum random `delay` added to each run",
+		)
+		count = flag.Uint(
+			"count",
+			0,
+			"Number of `runs` to make, or 0 for no limit",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Settings may also come from a config file, but flags given on the
+	command line take precedence. */
+	cfg := Config{
+		NoSummary: noSummary,
+		Interval:  (*jsonDuration)(interval),
+		Jitter:    (*jsonDuration)(jitter),
+		Count:     count,
+	}
+	if "" != *configFile {
+		if err := loadConfig(&cfg, *configFile); nil != err {
+			log.Fatalf(
+				"Error loading config from %s: %s",
+				*configFile,
+				err,
+			)
+		}
+	}
+	if *printCfg {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(cfg); nil != err {
+			log.Fatalf("Error printing config: %s", err)
+		}
+		return
+	}
+
+	/* Make sure we have a sensible interval. */
+	if 0 >= *interval {
+		log.Fatalf("Interval must be positive")
+	}
+
+	/* Run every interval, skipping runs which would overlap. */
+	var (
+		ctx     = context.Background()
+		ticker  = time.NewTicker(*interval)
+		running atomic.Bool
+		wg      sync.WaitGroup
+	)
+	defer ticker.Stop()
+	for n := uint(0); 0 == *count || n < *count; {
+		/* Wait for the next tick, except for the first run. */
+		if 0 != n {
+			<-ticker.C
+		}
+
+		/* Be a bit less predictable, if we're meant to be. */
+		if 0 < *jitter {
+			time.Sleep(rand.N(*jitter))
+		}
+
+		/* Don't start a run if the last one's still going. */
+		if !running.CompareAndSwap(false, true) {
+			log.Printf("Previous run overran, skipping this one")
+			continue
+		}
+		n++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer running.Store(false)
+			runOnce(ctx)
+		}()
+	}
+
+	/* Wait for the last run to finish. */
+	wg.Wait()
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done in %s.",
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+}
+
+/* runOnce is called every interval. */
+func runOnce(ctx context.Context) {
+	log.Printf("Running")
+}
+
+// loadConfig loads the JSON config file named name into cfg.  Unknown fields
+// are an error.  Flags given on the command line keep their values.
+func loadConfig(cfg *Config, name string) error {
+	/* Note what was set on the command line, to put back later. */
+	set := make(map[*flag.Flag]string)
+	flag.Visit(func(f *flag.Flag) { set[f] = f.Value.String() })
+
+	/* Load the file. */
+	f, err := os.Open(name)
+	if nil != err {
+		return err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); nil != err {
+		return err
+	}
+
+	/* Command line wins. */
+	for f, v := range set {
+		if err := f.Value.Set(v); nil != err {
+			return fmt.Errorf("restoring -%s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
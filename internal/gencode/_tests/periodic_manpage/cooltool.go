@@ -0,0 +1,207 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* Version and BuildTime may be set at build time with
+	-ldflags "-X main.Version=... -X main.BuildTime=...".  If not, they'll
+	be taken from the module and VCS information, if available. */
+	Version   string
+	BuildTime string
+)
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		printVer = flag.Bool(
+			"version",
+			false,
+			"Print version information and exit",
+		)
+		interval = flag.Duration(
+			"interval",
+			time.Minute,
+			"Run `interval`",
+		)
+		jitter = flag.Duration(
+			"jitter",
+			0,
+			"Maximum random `delay` added to each run",
+		)
+		count = flag.Uint(
+			"count",
+			0,
+			"Number of `runs` to make, or 0 for no limit",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+
+	/* Flags may also be set with environment variables. */
+	envFlags()
+	flag.Parse()
+
+	/* If all we're doing is printing the version, life's easy. */
+	if *printVer {
+		printVersion()
+		return
+	}
+
+	/* Make sure we have a sensible interval. */
+	if 0 >= *interval {
+		log.Fatalf("Interval must be positive")
+	}
+
+	/* Run every interval, skipping runs which would overlap. */
+	var (
+		ctx     = context.Background()
+		ticker  = time.NewTicker(*interval)
+		running atomic.Bool
+		wg      sync.WaitGroup
+	)
+	defer ticker.Stop()
+	for n := uint(0); 0 == *count || n < *count; {
+		/* Wait for the next tick, except for the first run. */
+		if 0 != n {
+			<-ticker.C
+		}
+
+		/* Be a bit less predictable, if we're meant to be. */
+		if 0 < *jitter {
+			time.Sleep(rand.N(*jitter))
+		}
+
+		/* Don't start a run if the last one's still going. */
+		if !running.CompareAndSwap(false, true) {
+			log.Printf("Previous run overran, skipping this one")
+			continue
+		}
+		n++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer running.Store(false)
+			runOnce(ctx)
+		}()
+	}
+
+	/* Wait for the last run to finish. */
+	wg.Wait()
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done in %s.",
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+}
+
+/* runOnce is called every interval. */
+func runOnce(ctx context.Context) {
+	log.Printf("Running")
+}
+
+// envFlags sets flags from environment variables named after the flags, in
+// upper case with dashes replaced by underscores and prefixed with
+// COOLTOOL_.  It also adds the variables' names to the flags' usage.
+// It must be called before flag.Parse, so flags given on the command line
+// take precedence.
+func envFlags() {
+	flag.VisitAll(func(f *flag.Flag) {
+		n := "COOLTOOL_" + strings.ToUpper(
+			strings.ReplaceAll(f.Name, "-", "_"),
+		)
+		f.Usage += " [$" + n + "]"
+		v, ok := os.LookupEnv(n)
+		if !ok {
+			return
+		}
+		if err := f.Value.Set(v); nil != err {
+			log.Fatalf("Invalid value %q for $%s: %s", v, n, err)
+		}
+	})
+}
+
+// printVersion prints the program's version, VCS revision and commit time,
+// whether the working tree had uncommitted changes, and when it was built.
+// Version and BuildTime, if set with -ldflags, take precedence.
+func printVersion() {
+	var (
+		version   = Version
+		revision  = "unknown"
+		committed = "unknown"
+		dirty     = "unknown"
+		built     = BuildTime
+	)
+
+	/* Fill in the blanks from the build info. */
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if "" == version {
+			version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				revision = s.Value
+			case "vcs.time":
+				committed = s.Value
+			case "vcs.modified":
+				dirty = s.Value
+			}
+		}
+	}
+	if "" == version {
+		version = "unknown"
+	}
+	if "" == built {
+		built = "unknown"
+	}
+
+	fmt.Printf("Version:   %s\n", version)
+	fmt.Printf("Revision:  %s\n", revision)
+	fmt.Printf("Committed: %s\n", committed)
+	fmt.Printf("Dirty:     %s\n", dirty)
+	fmt.Printf("Built:     %s\n", built)
+}
@@ -0,0 +1,203 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NDone keeps track of the number of things we've done. */
+	NDone atomic.Uint64
+
+	/* NTotal is the total number of things to do, if known. */
+	NTotal atomic.Uint64
+)
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		progress = flag.Duration(
+			"progress",
+			0,
+			"Log progress every `interval` (0 for never)",
+		)
+		interval = flag.Duration(
+			"interval",
+			time.Minute,
+			"Run `interval`",
+		)
+		jitter = flag.Duration(
+			"jitter",
+			0,
+			"Maximum random `delay` added to each run",
+		)
+		count = flag.Uint(
+			"count",
+			0,
+			"Number of `runs` to make, or 0 for no limit",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Report progress every so often, if we're meant to. */
+	stopProgress := startProgress(*progress)
+
+	/* Make sure we have a sensible interval. */
+	if 0 >= *interval {
+		log.Fatalf("Interval must be positive")
+	}
+
+	/* We may know how many runs we'll make. */
+	NTotal.Store(uint64(*count))
+
+	/* Run every interval, skipping runs which would overlap. */
+	var (
+		ctx     = context.Background()
+		ticker  = time.NewTicker(*interval)
+		running atomic.Bool
+		wg      sync.WaitGroup
+	)
+	defer ticker.Stop()
+	for n := uint(0); 0 == *count || n < *count; {
+		/* Wait for the next tick, except for the first run. */
+		if 0 != n {
+			<-ticker.C
+		}
+
+		/* Be a bit less predictable, if we're meant to be. */
+		if 0 < *jitter {
+			time.Sleep(rand.N(*jitter))
+		}
+
+		/* Don't start a run if the last one's still going. */
+		if !running.CompareAndSwap(false, true) {
+			log.Printf("Previous run overran, skipping this one")
+			continue
+		}
+		n++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer running.Store(false)
+			runOnce(ctx)
+		}()
+	}
+
+	/* Wait for the last run to finish. */
+	wg.Wait()
+
+	/* No more progress reports. */
+	stopProgress()
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done.  Finished %d in %s.",
+			NDone.Load(),
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+}
+
+/* runOnce is called every interval. */
+func runOnce(ctx context.Context) {
+	defer NDone.Add(1)
+	log.Printf("Running")
+}
+
+// startProgress logs progress every interval until the returned function is
+// called.  If interval isn't positive, startProgress does nothing.
+func startProgress(interval time.Duration) (stop func()) {
+	if 0 >= interval {
+		return func() {}
+	}
+	var (
+		ticker  = time.NewTicker(interval)
+		done    = make(chan struct{})
+		stopped = make(chan struct{})
+	)
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				logProgress()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+	}
+}
+
+/* logProgress logs how much we've done and, if we know, how much is left. */
+func logProgress() {
+	var (
+		done  = NDone.Load()
+		total = NTotal.Load()
+		rate  = float64(done) / time.Since(ProgramStart).Seconds()
+	)
+
+	/* If we don't know how much there is to do, life's easy. */
+	if 0 == total {
+		log.Printf("Progress: %d done (%.2f/s)", done, rate)
+		return
+	}
+
+	/* Work out how much longer we've got. */
+	eta := "unknown"
+	if 0 != done && done < total {
+		eta = time.Duration(
+			float64(total-done) / rate * float64(time.Second),
+		).Round(time.Second).String()
+	}
+	log.Printf(
+		"Progress: %d/%d done (%.2f/s), ETA %s",
+		done,
+		total,
+		rate,
+		eta,
+	)
+}
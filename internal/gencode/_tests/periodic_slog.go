@@ -0,0 +1,176 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+)
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		logFormat = flag.String(
+			"log-format",
+			"text",
+			"Log `format` (text or json)",
+		)
+		interval = flag.Duration(
+			"interval",
+			time.Minute,
+			"Run `interval`",
+		)
+		jitter = flag.Duration(
+			"jitter",
+			0,
+			"Maximum random `delay` added to each run",
+		)
+		count = flag.Uint(
+			"count",
+			0,
+			"Number of `runs` to make, or 0 for no limit",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Set up structured logging. */
+	lo := &slog.HandlerOptions{}
+	var lh slog.Handler
+	switch *logFormat {
+	case "text":
+		lh = slog.NewTextHandler(os.Stderr, lo)
+	case "json":
+		lh = slog.NewJSONHandler(os.Stderr, lo)
+	default:
+		log.Fatalf("Unknown log format %q", *logFormat)
+	}
+	slog.SetDefault(slog.New(lh))
+
+	/* Finish up nicely on the first signal, exit on the second. */
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handleSignals(cancel)
+
+	/* Make sure we have a sensible interval. */
+	if 0 >= *interval {
+		slog.Error("Interval must be positive", "interval", *interval)
+		os.Exit(1)
+	}
+
+	/* Run every interval, skipping runs which would overlap. */
+	var (
+		ticker  = time.NewTicker(*interval)
+		running atomic.Bool
+		wg      sync.WaitGroup
+	)
+	defer ticker.Stop()
+	for n := uint(0); (0 == *count || n < *count) && nil == ctx.Err(); {
+		/* Wait for the next tick, except for the first run. */
+		if 0 != n {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				continue /* Loop condition will stop us. */
+			}
+		}
+
+		/* Be a bit less predictable, if we're meant to be. */
+		if 0 < *jitter {
+			select {
+			case <-time.After(rand.N(*jitter)):
+			case <-ctx.Done():
+				continue /* Loop condition will stop us. */
+			}
+		}
+
+		/* Don't start a run if the last one's still going. */
+		if !running.CompareAndSwap(false, true) {
+			slog.Warn("Previous run overran, skipping this one")
+			continue
+		}
+		n++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer running.Store(false)
+			runOnce(ctx)
+		}()
+	}
+
+	/* Wait for the last run to finish. */
+	wg.Wait()
+
+	/* All done. */
+	if !*noSummary {
+		/* Note if we didn't quite finish. */
+		how := "Done"
+		if nil != ctx.Err() {
+			how = "Interrupted"
+		}
+		slog.Info(
+			how,
+			"elapsed",
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+}
+
+/* runOnce is called every interval. */
+func runOnce(ctx context.Context) {
+	slog.Info("Running")
+}
+
+// handleSignals calls cancel when SIGINT or SIGTERM is caught and terminates
+// the program if either is caught again.
+func handleSignals(cancel context.CancelFunc) {
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	slog.Warn(
+		"Caught signal, finishing up; again to exit now",
+		"signal", <-ch,
+	)
+	cancel()
+	slog.Error("Caught signal, exiting", "signal", <-ch)
+	os.Exit(1)
+}
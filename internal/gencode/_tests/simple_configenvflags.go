@@ -0,0 +1,186 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+)
+
+// Config holds settings which may be loaded from a JSON file with -config.
+// Its fields point to the corresponding flags' values.
+type Config struct {
+	/* Don't print a summary on exit. */
+	NoSummary *bool `json:"no-summary"`
+}
+
+// jsonDuration is a time.Duration which is represented in JSON as a string
+// understood by time.ParseDuration.
+type jsonDuration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *jsonDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); nil != err {
+		return err
+	}
+	pd, err := time.ParseDuration(s)
+	if nil != err {
+		return err
+	}
+	*d = jsonDuration(pd)
+	return nil
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		configFile = flag.String(
+			"config",
+			"",
+			"Load settings from JSON `file`",
+		)
+		printCfg = flag.Bool(
+			"print-config",
+			false,
+			"Print the effective settings as JSON and exit",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+
+	/* Flags may also be set with environment variables. */
+	envFlags()
+	flag.Parse()
+
+	/* Settings may also come from a config file, but flags given on the
+	command line or set from the environment take precedence. */
+	cfg := Config{
+		NoSummary: noSummary,
+	}
+	if "" != *configFile {
+		if err := loadConfig(&cfg, *configFile); nil != err {
+			log.Fatalf(
+				"Error loading config from %s: %s",
+				*configFile,
+				err,
+			)
+		}
+	}
+	if *printCfg {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(cfg); nil != err {
+			log.Fatalf("Error printing config: %s", err)
+		}
+		return
+	}
+
+	/* TODO: Meat and Potatoes. */
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done in %s.",
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+}
+
+// loadConfig loads the JSON config file named name into cfg.  Unknown fields
+// are an error.  Flags given on the command line or set from the environment
+// keep their values.
+func loadConfig(cfg *Config, name string) error {
+	/* Note what was set on the command line or from the environment, to
+	put back later. */
+	set := make(map[*flag.Flag]string)
+	flag.Visit(func(f *flag.Flag) { set[f] = f.Value.String() })
+	for f := range envSet {
+		set[f] = f.Value.String()
+	}
+
+	/* Load the file. */
+	f, err := os.Open(name)
+	if nil != err {
+		return err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); nil != err {
+		return err
+	}
+
+	/* Command line and environment win. */
+	for f, v := range set {
+		if err := f.Value.Set(v); nil != err {
+			return fmt.Errorf("restoring -%s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// envSet holds the flags set by envFlags, so loadConfig doesn't override them.
+var envSet = make(map[*flag.Flag]struct{})
+
+// envFlags sets flags from environment variables named after the flags, in
+// upper case with dashes replaced by underscores and prefixed with
+// COOLTOOL_.  It also adds the variables' names to the flags' usage.
+// It must be called before flag.Parse, so flags given on the command line
+// take precedence.
+func envFlags() {
+	flag.VisitAll(func(f *flag.Flag) {
+		n := "COOLTOOL_" + strings.ToUpper(
+			strings.ReplaceAll(f.Name, "-", "_"),
+		)
+		f.Usage += " [$" + n + "]"
+		v, ok := os.LookupEnv(n)
+		if !ok {
+			return
+		}
+		if err := f.Value.Set(v); nil != err {
+			log.Fatalf("Invalid value %q for $%s: %s", v, n, err)
+		}
+		envSet[f] = struct{}{}
+	})
+}
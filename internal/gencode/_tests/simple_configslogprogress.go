@@ -0,0 +1,272 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* NDone keeps track of the number of things we've done. */
+	NDone atomic.Uint64
+
+	/* NTotal is the total number of things to do, if known. */
+	NTotal atomic.Uint64
+
+	/* Verbosef logs at the debug level, only enabled with -verbose. */
+	Verbosef = func(format string, v ...any) {
+		slog.Debug(fmt.Sprintf(format, v...))
+	}
+)
+
+// Config holds settings which may be loaded from a JSON file with -config.
+// Its fields point to the corresponding flags' values.
+type Config struct {
+	/* Don't print a summary on exit. */
+	NoSummary *bool `json:"no-summary"`
+	/* Enable verbose logging. */
+	Verbose *bool `json:"verbose"`
+	/* Log format, text or json. */
+	LogFormat *string `json:"log-format"`
+	/* Progress logging interval. */
+	Progress *jsonDuration `json:"progress"`
+}
+
+// jsonDuration is a time.Duration which is represented in JSON as a string
+// understood by time.ParseDuration.
+type jsonDuration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *jsonDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); nil != err {
+		return err
+	}
+	pd, err := time.ParseDuration(s)
+	if nil != err {
+		return err
+	}
+	*d = jsonDuration(pd)
+	return nil
+}
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		verbOn = flag.Bool(
+			"verbose",
+			false,
+			"Enable verbose logging",
+		)
+		configFile = flag.String(
+			"config",
+			"",
+			"Load settings from JSON `file`",
+		)
+		printCfg = flag.Bool(
+			"print-config",
+			false,
+			"Print the effective settings as JSON and exit",
+		)
+		logFormat = flag.String(
+			"log-format",
+			"text",
+			"Log `format` (text or json)",
+		)
+		progress = flag.Duration(
+			"progress",
+			0,
+			"Log progress every `interval` (0 for never)",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Settings may also come from a config file, but flags given on the
+	command line take precedence. */
+	cfg := Config{
+		NoSummary: noSummary,
+		Verbose:   verbOn,
+		LogFormat: logFormat,
+		Progress:  (*jsonDuration)(progress),
+	}
+	if "" != *configFile {
+		if err := loadConfig(&cfg, *configFile); nil != err {
+			log.Fatalf(
+				"Error loading config from %s: %s",
+				*configFile,
+				err,
+			)
+		}
+	}
+	if *printCfg {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(cfg); nil != err {
+			log.Fatalf("Error printing config: %s", err)
+		}
+		return
+	}
+
+	/* Set up structured logging. */
+	lo := &slog.HandlerOptions{}
+	if *verbOn {
+		lo.Level = slog.LevelDebug
+	}
+	var lh slog.Handler
+	switch *logFormat {
+	case "text":
+		lh = slog.NewTextHandler(os.Stderr, lo)
+	case "json":
+		lh = slog.NewJSONHandler(os.Stderr, lo)
+	default:
+		log.Fatalf("Unknown log format %q", *logFormat)
+	}
+	slog.SetDefault(slog.New(lh))
+
+	/* Report progress every so often, if we're meant to. */
+	stopProgress := startProgress(*progress)
+
+	/* TODO: Meat and Potatoes. */
+
+	/* No more progress reports. */
+	stopProgress()
+
+	/* All done. */
+	if !*noSummary {
+		slog.Info(
+			"Done",
+			"elapsed",
+			time.Since(ProgramStart).Round(time.Millisecond),
+			"done", NDone.Load(),
+		)
+	}
+}
+
+// loadConfig loads the JSON config file named name into cfg.  Unknown fields
+// are an error.  Flags given on the command line keep their values.
+func loadConfig(cfg *Config, name string) error {
+	/* Note what was set on the command line, to put back later. */
+	set := make(map[*flag.Flag]string)
+	flag.Visit(func(f *flag.Flag) { set[f] = f.Value.String() })
+
+	/* Load the file. */
+	f, err := os.Open(name)
+	if nil != err {
+		return err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); nil != err {
+		return err
+	}
+
+	/* Command line wins. */
+	for f, v := range set {
+		if err := f.Value.Set(v); nil != err {
+			return fmt.Errorf("restoring -%s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// startProgress logs progress every interval until the returned function is
+// called.  If interval isn't positive, startProgress does nothing.
+func startProgress(interval time.Duration) (stop func()) {
+	if 0 >= interval {
+		return func() {}
+	}
+	var (
+		ticker  = time.NewTicker(interval)
+		done    = make(chan struct{})
+		stopped = make(chan struct{})
+	)
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				logProgress()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+	}
+}
+
+/* logProgress logs how much we've done and, if we know, how much is left. */
+func logProgress() {
+	var (
+		done  = NDone.Load()
+		total = NTotal.Load()
+		rate  = float64(done) / time.Since(ProgramStart).Seconds()
+	)
+
+	/* If we don't know how much there is to do, life's easy. */
+	if 0 == total {
+		log.Printf("Progress: %d done (%.2f/s)", done, rate)
+		return
+	}
+
+	/* Work out how much longer we've got. */
+	eta := "unknown"
+	if 0 != done && done < total {
+		eta = time.Duration(
+			float64(total-done) / rate * float64(time.Second),
+		).Round(time.Second).String()
+	}
+	log.Printf(
+		"Progress: %d/%d done (%.2f/s), ETA %s",
+		done,
+		total,
+		rate,
+		eta,
+	)
+}
@@ -0,0 +1,84 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+)
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+
+	/* Flags may also be set with environment variables. */
+	envFlags()
+	flag.Parse()
+
+	/* TODO: Meat and Potatoes. */
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done in %s.",
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+}
+
+// envFlags sets flags from environment variables named after the flags, in
+// upper case with dashes replaced by underscores and prefixed with
+// COOLTOOL_.  It also adds the variables' names to the flags' usage.
+// It must be called before flag.Parse, so flags given on the command line
+// take precedence.
+func envFlags() {
+	flag.VisitAll(func(f *flag.Flag) {
+		n := "COOLTOOL_" + strings.ToUpper(
+			strings.ReplaceAll(f.Name, "-", "_"),
+		)
+		f.Usage += " [$" + n + "]"
+		v, ok := os.LookupEnv(n)
+		if !ok {
+			return
+		}
+		if err := f.Value.Set(v); nil != err {
+			log.Fatalf("Invalid value %q for $%s: %s", v, n, err)
+		}
+	})
+}
@@ -0,0 +1,179 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	_ "expvar"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+)
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		cpuProfile = flag.String(
+			"cpuprofile",
+			"",
+			"Write a CPU profile to `file`",
+		)
+		memProfile = flag.String(
+			"memprofile",
+			"",
+			"Write a memory profile to `file` before exiting",
+		)
+		traceFile = flag.String(
+			"trace",
+			"",
+			"Write an execution trace to `file`",
+		)
+		debugListen = flag.String(
+			"debug-listen",
+			"",
+			"Serve pprof and expvar on `address`",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Profile and trace, if we're meant to. */
+	stopProfiling := startProfiling(
+		*cpuProfile,
+		*memProfile,
+		*traceFile,
+		*debugListen,
+	)
+
+	/* TODO: Meat and Potatoes. */
+
+	/* Finish profiling and tracing. */
+	stopProfiling()
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done in %s.",
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+}
+
+// startProfiling starts CPU profiling and execution tracing to the named files
+// and serves net/http/pprof and expvar on debugAddr, each only if the name or
+// address isn't empty.  The returned function stops profiling and tracing and
+// writes a memory profile to memFile, if it isn't empty.
+func startProfiling(cpuFile, memFile, traceFile, debugAddr string) func() {
+	var stops []func()
+
+	/* Profile the CPU. */
+	if "" != cpuFile {
+		f, err := os.Create(cpuFile)
+		if nil != err {
+			log.Fatalf("Error creating CPU profile: %s", err)
+		}
+		if err := pprof.StartCPUProfile(f); nil != err {
+			log.Fatalf("Error starting CPU profile: %s", err)
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			if err := f.Close(); nil != err {
+				log.Printf("Error closing CPU profile: %s", err)
+			}
+		})
+	}
+
+	/* Trace execution. */
+	if "" != traceFile {
+		f, err := os.Create(traceFile)
+		if nil != err {
+			log.Fatalf("Error creating trace file: %s", err)
+		}
+		if err := trace.Start(f); nil != err {
+			log.Fatalf("Error starting trace: %s", err)
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			if err := f.Close(); nil != err {
+				log.Printf("Error closing trace file: %s", err)
+			}
+		})
+	}
+
+	/* Profile memory, once we're done. */
+	if "" != memFile {
+		stops = append(stops, func() {
+			f, err := os.Create(memFile)
+			if nil != err {
+				log.Printf(
+					"Error creating memory profile: %s",
+					err,
+				)
+				return
+			}
+			defer f.Close()
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(f); nil != err {
+				log.Printf(
+					"Error writing memory profile: %s",
+					err,
+				)
+			}
+		})
+	}
+
+	/* Serve pprof and expvar. */
+	if "" != debugAddr {
+		l, err := net.Listen("tcp", debugAddr)
+		if nil != err {
+			log.Fatalf("Error listening on %s: %s", debugAddr, err)
+		}
+		log.Printf("Serving pprof and expvar on %s", l.Addr())
+		go func() {
+			log.Printf("Debug server died: %s", http.Serve(l, nil))
+		}()
+	}
+
+	return func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+)
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		logFormat = flag.String(
+			"log-format",
+			"text",
+			"Log `format` (text or json)",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* Set up structured logging. */
+	lo := &slog.HandlerOptions{}
+	var lh slog.Handler
+	switch *logFormat {
+	case "text":
+		lh = slog.NewTextHandler(os.Stderr, lo)
+	case "json":
+		lh = slog.NewJSONHandler(os.Stderr, lo)
+	default:
+		log.Fatalf("Unknown log format %q", *logFormat)
+	}
+	slog.SetDefault(slog.New(lh))
+
+	/* TODO: Meat and Potatoes. */
+
+	/* All done. */
+	if !*noSummary {
+		slog.Info(
+			"Done",
+			"elapsed",
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+}
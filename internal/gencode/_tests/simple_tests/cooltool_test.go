@@ -0,0 +1,61 @@
+package main
+
+/*
+ * cooltool_test.go
+ * Tests for cooltool.go
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// maxUsageWidth is the maximum width of the usage message before the options.
+const maxUsageWidth = 79
+
+// TestUsage builds the program and checks that the top part of the usage
+// message isn't too wide.  Building takes a while, so it's skipped with -short,
+// as the Makefile's test target does its own check.
+func TestUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Not building with -short")
+	}
+
+	/* Build the program. */
+	bin := filepath.Join(t.TempDir(), "cooltool")
+	o, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput()
+	if nil != err {
+		t.Fatalf("Error building: %s\n%s", err, o)
+	}
+
+	/* Get the usage, minus the temporary directory. */
+	if o, err = exec.Command(bin, "-h").CombinedOutput(); nil != err {
+		t.Fatalf("Error getting usage: %s\n%s", err, o)
+	}
+	o = bytes.Replace(o, []byte(filepath.Dir(bin)+"/"), nil, 1)
+
+	/* Make sure it's not too wide, at least before the options. */
+	scanner := bufio.NewScanner(bytes.NewReader(o))
+	for scanner.Scan() {
+		l := scanner.Text()
+		if "Options:" == l {
+			break
+		}
+		if maxUsageWidth < len(l) {
+			t.Errorf("Long usage line: %s", l)
+		}
+	}
+	if err := scanner.Err(); nil != err {
+		t.Fatalf("Error reading usage: %s", err)
+	}
+	if !strings.Contains(string(o), "Options:") {
+		t.Errorf("Usage missing options:\n%s", o)
+	}
+}
@@ -0,0 +1,119 @@
+// Program cooltool - A cool program
+package main
+
+/*
+ * cooltool.go
+ * A cool program
+ * By MysteryDev
+ * Created in the past
+ * Last Modified in the past
+ */
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+var (
+	/* ProgramStart notes when the program has started for printing the
+	elapsed time when the program ends. */
+	ProgramStart = time.Now()
+
+	/* Version and BuildTime may be set at build time with
+	-ldflags "-X main.Version=... -X main.BuildTime=...".  If not, they'll
+	be taken from the module and VCS information, if available. */
+	Version   string
+	BuildTime string
+)
+
+func main() {
+	/* Command-line flags. */
+	var (
+		noSummary = flag.Bool(
+			"no-summary",
+			false,
+			"Don't print a summary on exit",
+		)
+		printVer = flag.Bool(
+			"version",
+			false,
+			"Print version information and exit",
+		)
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: %s [options]
+
+A cool program
+
+Options:
+`,
+			os.Args[0],
+		)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	/* If all we're doing is printing the version, life's easy. */
+	if *printVer {
+		printVersion()
+		return
+	}
+
+	/* TODO: Meat and Potatoes. */
+
+	/* All done. */
+	if !*noSummary {
+		log.Printf(
+			"Done in %s.",
+			time.Since(ProgramStart).Round(time.Millisecond),
+		)
+	}
+}
+
+// printVersion prints the program's version, VCS revision and commit time,
+// whether the working tree had uncommitted changes, and when it was built.
+// Version and BuildTime, if set with -ldflags, take precedence.
+func printVersion() {
+	var (
+		version   = Version
+		revision  = "unknown"
+		committed = "unknown"
+		dirty     = "unknown"
+		built     = BuildTime
+	)
+
+	/* Fill in the blanks from the build info. */
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if "" == version {
+			version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				revision = s.Value
+			case "vcs.time":
+				committed = s.Value
+			case "vcs.modified":
+				dirty = s.Value
+			}
+		}
+	}
+	if "" == version {
+		version = "unknown"
+	}
+	if "" == built {
+		built = "unknown"
+	}
+
+	fmt.Printf("Version:   %s\n", version)
+	fmt.Printf("Revision:  %s\n", revision)
+	fmt.Printf("Committed: %s\n", committed)
+	fmt.Printf("Dirty:     %s\n", dirty)
+	fmt.Printf("Built:     %s\n", built)
+}
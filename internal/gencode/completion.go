@@ -0,0 +1,137 @@
+package gencode
+
+/*
+ * completion.go
+ * Generate shell completion scripts
+ * By J. Stuart McMurray
+ * Created 20261019
+ * Last Modified 20261019
+ */
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// completionTemplate is the subtemplate which generates a completion script
+// from a Completion.
+const completionTemplate = "shellCompletion"
+
+// DefaultShell is the default shell for which to generate completion scripts.
+const DefaultShell = "bash"
+
+// Shells are the shells for which we can generate completion scripts.
+var Shells = []string{"bash", "fish", "zsh"}
+
+// Completion describes the command for which to generate a completion script.
+type Completion struct {
+	Name   string           /* Command name. */
+	Shell  string           /* Shell, one of Shells. */
+	Author string           /* Author, if not empty. */
+	Today  string           /* Creation date, if not empty. */
+	Flags  []CompletionFlag /* Command's flags. */
+}
+
+// CompletionFlag is a flag and its possible values.
+type CompletionFlag struct {
+	UsageFlag
+	Values []string /* Values to complete, if known. */
+}
+
+// FlagSetCompletion returns a Completion for the flags in fs.
+func FlagSetCompletion(name, shell string, fs *flag.FlagSet) Completion {
+	c := Completion{Name: name, Shell: shell}
+	for _, uf := range usageFlags(fs) {
+		c.Flags = append(c.Flags, CompletionFlag{UsageFlag: uf})
+	}
+	return c
+}
+
+// GenerateCompletion writes c's completion script to w.
+func GenerateCompletion(w io.Writer, c Completion) error {
+	if !slices.Contains(Shells, c.Shell) {
+		return fmt.Errorf("unknown shell %q", c.Shell)
+	}
+	return templates[DefaultTType].ExecuteTemplate(w, completionTemplate, c)
+}
+
+// CompletionSpec returns the Completion for d.Type's tool.
+func (d Data) CompletionSpec() (Completion, error) {
+	if !slices.Contains(Shells, d.Shell) {
+		return Completion{}, fmt.Errorf("unknown shell %q", d.Shell)
+	}
+	ufs, err := d.UsageFlags()
+	if nil != err {
+		return Completion{}, err
+	}
+	c := Completion{
+		Name:   d.Name,
+		Shell:  d.Shell,
+		Author: d.Author,
+		Today:  d.Today,
+	}
+	for _, uf := range ufs {
+		c.Flags = append(c.Flags, CompletionFlag{UsageFlag: uf})
+	}
+	return c, nil
+}
+
+// CompletionFilename returns the name of d's completion script.
+func (d Data) CompletionFilename() string {
+	return Completion{Name: d.Name, Shell: d.Shell}.Filename()
+}
+
+// Filename returns the conventional name of c's completion script.
+func (c Completion) Filename() string {
+	switch c.Shell {
+	case "fish":
+		return c.Name + ".fish"
+	case "zsh":
+		return "_" + c.Name
+	default:
+		return c.Name + ".bash"
+	}
+}
+
+// FuncName returns c.Name with anything other than letters and digits
+// replaced with underscores, for naming shell functions.
+func (c Completion) FuncName() string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, c.Name)
+}
+
+// Words returns f's values separated by spaces.
+func (f CompletionFlag) Words() string { return strings.Join(f.Values, " ") }
+
+// ZshSpec returns f's single-quoted zsh _arguments spec.
+func (f CompletionFlag) ZshSpec() string {
+	spec := "-" + f.Name + "[" + strings.NewReplacer(
+		`\`, `\\`,
+		`[`, `\[`,
+		`]`, `\]`,
+	).Replace(f.Usage) + "]"
+	switch {
+	case "" == f.Arg:
+	case 0 != len(f.Values):
+		spec += ":" + f.Arg + ":(" + f.Words() + ")"
+	default:
+		spec += ":" + f.Arg + ":_default"
+	}
+	return "'" + strings.ReplaceAll(spec, `'`, `'\''`) + "'"
+}
+
+// FishUsage returns f.Usage single-quoted for fish.
+func (f CompletionFlag) FishUsage() string {
+	return "'" + strings.NewReplacer(
+		`\`, `\\`,
+		`'`, `\'`,
+	).Replace(f.Usage) + "'"
+}
@@ -0,0 +1,63 @@
+package gencode
+
+/*
+ * completion_test.go
+ * Tests for completion.go
+ * By J. Stuart McMurray
+ * Created 20261019
+ * Last Modified 20261019
+ */
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenerateCompletion(t *testing.T) {
+	/* Unknown shells shouldn't work. */
+	if err := GenerateCompletion(&bytes.Buffer{}, Completion{
+		Name:  "toolskel",
+		Shell: "csh",
+	}); nil == err {
+		t.Errorf("No error for unknown shell")
+	}
+}
+
+func TestCompletionFlagZshSpec(t *testing.T) {
+	for _, c := range []struct {
+		flag CompletionFlag
+		want string
+	}{{
+		flag: CompletionFlag{UsageFlag: UsageFlag{
+			Name:  "no-summary",
+			Usage: "Don't print a summary [on exit]",
+		}},
+		want: `'-no-summary[Don'\''t print a summary \[on exit\]]'`,
+	}, {
+		flag: CompletionFlag{UsageFlag: UsageFlag{
+			Name:  "count",
+			Arg:   "runs",
+			Usage: "Number of runs",
+		}},
+		want: `'-count[Number of runs]:runs:_default'`,
+	}, {
+		flag: CompletionFlag{
+			UsageFlag: UsageFlag{
+				Name:  "shell",
+				Arg:   "shell",
+				Usage: "Shell",
+			},
+			Values: []string{"bash", "zsh"},
+		},
+		want: `'-shell[Shell]:shell:(bash zsh)'`,
+	}} {
+		if got := c.flag.ZshSpec(); got != c.want {
+			t.Errorf(
+				"ZshSpec(%s)\n got: %s\nwant: %s",
+				c.flag.Name,
+				got,
+				c.want,
+			)
+		}
+	}
+}
@@ -5,7 +5,7 @@ package gencode
  * Data we pass to templates
  * By J. Stuart McMurray
  * Created 20230418
- * Last Modified 20240419
+ * Last Modified 20261019
  */
 
 import (
@@ -14,9 +14,11 @@ import (
 	"maps"
 	"os"
 	"path/filepath"
-	"reflect"
 	"sort"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // The following default values are compile-time settable.
@@ -24,6 +26,7 @@ var (
 	defaultDescription = "A cool program"
 	defaultProgramName = "cooltool"
 	defaultAuthorName  = "MysteryDev"
+	defaultFuncName    = "Parse"
 )
 
 // Data is used to pass data to the template being executed.
@@ -35,11 +38,68 @@ type Data struct {
 	Today        string              /* Curent date. */
 	SummaryCount bool                /* Print count with summary. */
 	TagLog       bool                /* Tag logs with argv[0]. */
-	PkgType      string              /* Package or Program (default)  */
 	Verbose      bool                /* -verbose */
+	Stream       bool                /* Stream tasks from stdin. */
+	Results      bool                /* Collect and print results. */
+	Ordered      bool                /* Print results in task order. */
+	RateLimit    bool                /* -rate */
+	TaskTimeout  bool                /* -task-timeout */
+	Retries      bool                /* -retries and -backoff */
+	Checkpoint   bool                /* -state */
+	Progress     bool                /* -progress */
+	Interrupt    bool                /* Finish nicely on SIGINT/SIGTERM. */
+	Context      bool                /* Thread a context.Context. */
+	Slog         bool                /* Use log/slog. */
+	Version      bool                /* -version */
+	EnvFlags     bool                /* Flags settable from the env. */
+	Config       bool                /* -config and -print-config */
+	Profiling    bool                /* Profiling and tracing flags. */
+	Tests        bool                /* Generate a _test.go file. */
+	Readme       bool                /* Generate a README.md. */
+	Manpage      bool                /* Generate a manual page. */
+	Completion   bool                /* Generate a completion script. */
+	Shell        string              /* Shell for completion scripts. */
+	MakeFlavor   string              /* Makefile flavor. */
+	Platforms    []string            /* GOOS/GOARCH release targets. */
+	InDir        bool                /* Generating into a directory. */
+	BinPath      string              /* Service's binary's path. */
+	ServiceUser  string              /* Service's user, if not default. */
+	Restart      string              /* Service's restart policy. */
+	EnvFile      string              /* Service's environment file. */
+	ForType      string              /* Tool type for companion types. */
+	Type         string              /* Tool type being generated. */
+	FuncName     string              /* Function to fuzz. */
+	Package      string              /* Package of the function to fuzz. */
 	Imports      map[string]struct{} /* Imported packages. */
 }
 
+// DefaultPlatforms are the default GOOS/GOARCH pairs for which to build
+// releases.
+var DefaultPlatforms = []string{
+	"darwin/amd64",
+	"darwin/arm64",
+	"linux/amd64",
+	"linux/arm64",
+	"openbsd/amd64",
+	"windows/amd64",
+}
+
+// BuildTarget is a GOOS/GOARCH pair for which to build.
+type BuildTarget struct {
+	OS   string
+	Arch string
+}
+
+// Suffix returns the suffix for the binary built for t, which is t's OS and
+// architecture, separated by a dash, with .exe for Windows.
+func (t BuildTarget) Suffix() string {
+	s := t.OS + "-" + t.Arch
+	if "windows" == t.OS {
+		s += ".exe"
+	}
+	return s
+}
+
 // SetDefaults makes sure every field of Data has a default value.
 func (d *Data) SetDefaults() {
 	/* Default name is current directory's base name, or cooltool if
@@ -57,6 +117,29 @@ func (d *Data) SetDefaults() {
 	setDefault(&d.Author, defaultAuthorName)
 	setDefault(&d.Today, "in the past")
 	setDefault(&d.Imports, make(map[string]struct{}))
+	setDefault(&d.ForType, DefaultTType)
+	setDefault(&d.FuncName, defaultFuncName)
+	setDefault(&d.Package, "main")
+	setDefault(&d.Shell, DefaultShell)
+	setDefault(&d.MakeFlavor, DefaultMakeFlavor)
+	setDefault(&d.BinPath, "/usr/local/bin/"+d.Name)
+	setDefault(&d.Restart, "on-failure")
+	setDefault(&d.Platforms, DefaultPlatforms)
+
+	/* Can't order results we don't have. */
+	if d.Ordered {
+		d.Results = true
+	}
+
+	/* Progress is measured in things done. */
+	if d.Progress {
+		d.SummaryCount = true
+	}
+
+	/* Interrupting is done by cancelling a context. */
+	if d.Interrupt {
+		d.Context = true
+	}
 }
 
 // Clone returns a copy of d.
@@ -69,6 +152,73 @@ func (d Data) copy() Data {
 // CmdDesc gets the command name and description, separated with a ": ".
 func (d Data) CmdDesc() string { return d.Name + " - " + d.Description }
 
+// EnvPrefix returns the prefix for environment variables which may be used in
+// place of flags.  It is d.Name in upper case with anything other than letters
+// and digits replaced with underscores, followed by an underscore.
+func (d Data) EnvPrefix() string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(d.Name)) + "_"
+}
+
+// FuncTestName returns d.FuncName with its first letter in upper case, for
+// naming fuzz tests and benchmarks.
+func (d Data) FuncTestName() string {
+	r, n := utf8.DecodeRuneInString(d.FuncName)
+	return string(unicode.ToUpper(r)) + d.FuncName[n:]
+}
+
+// BuildTargets returns d.Platforms as BuildTargets.  Whitespace around each
+// platform is ignored, as are empty platforms.
+func (d Data) BuildTargets() ([]BuildTarget, error) {
+	bts := make([]BuildTarget, 0, len(d.Platforms))
+	for _, p := range d.Platforms {
+		p = strings.TrimSpace(p)
+		if "" == p {
+			continue
+		}
+		os, arch, ok := strings.Cut(p, "/")
+		if !ok || "" == os || "" == arch ||
+			strings.Contains(arch, "/") ||
+			strings.ContainsFunc(p, unicode.IsSpace) {
+			return nil, fmt.Errorf("invalid platform %q", p)
+		}
+		bts = append(bts, BuildTarget{OS: os, Arch: arch})
+	}
+	return bts, nil
+}
+
+// ManTitle returns d.Name in upper case, for a manual page's title.
+func (d Data) ManTitle() string { return strings.ToUpper(d.Name) }
+
+// MdocDate returns d.Today in the format used by mdoc(7), or d.Today itself
+// if it's not a date.
+func (d Data) MdocDate() string {
+	t, err := time.Parse("20060102", d.Today)
+	if nil != err {
+		return d.Today
+	}
+	return t.Format("January 2, 2006")
+}
+
+// RcdUser returns d.ServiceUser or, if it's not set, d.Name with a leading
+// underscore, per OpenBSD convention.
+func (d Data) RcdUser() string {
+	if "" != d.ServiceUser {
+		return d.ServiceUser
+	}
+	return "_" + d.Name
+}
+
+// Underline returns s repeated once for each character in d.Name, for
+// underlining d.Name in Markdown.
+func (d Data) Underline(s string) string {
+	return strings.Repeat(s, utf8.RuneCountInString(d.Name))
+}
+
 // WithImports returns a copy of d with added imports.
 func (d Data) WithImports(imports ...string) Data {
 	n := d.copy()
@@ -79,21 +229,22 @@ func (d Data) WithImports(imports ...string) Data {
 }
 
 // ImportsBlock returns a block of text suitable for use in an imports() block.
-// Empty strings will be silently ignored.
+// Empty strings will be silently ignored.  Imports may be given a name, such
+// as _, by separating the name and path with a space.
 func (d Data) ImportsBlock() string {
 	var simps, ximps []string
 	/* Split the imports into stdlib and external. */
 	for imp := range d.Imports {
 		if "" == imp {
 			continue
-		} else if strings.Contains(imp, ".") {
+		} else if strings.Contains(importPath(imp), ".") {
 			ximps = append(ximps, imp)
 		} else {
 			simps = append(simps, imp)
 		}
 	}
-	sort.Strings(simps)
-	sort.Strings(ximps)
+	sortImports(simps)
+	sortImports(ximps)
 
 	/* Buffer for our block. */
 	var sb strings.Builder
@@ -104,32 +255,42 @@ func (d Data) ImportsBlock() string {
 		sb.WriteRune('\n')
 	}
 	for _, imp := range simps {
-		fmt.Fprintf(&sb, "\t%q\n", imp)
+		fmt.Fprintf(&sb, "\t%s\n", importSpec(imp))
 	}
 	if 0 != len(simps) && 0 != len(ximps) {
 		/* Blank line between stdlib and external imports. */
 		sb.WriteRune('\n')
 	}
 	for _, imp := range ximps {
-		fmt.Fprintf(&sb, "\t%q\n", imp)
+		fmt.Fprintf(&sb, "\t%s\n", importSpec(imp))
 	}
 	sb.WriteString(")")
 
 	return sb.String()
 }
 
-// WithSet returns a copy of d with the named field set to val.  It panics if
-// the field cannot be found or if val is not an appropriate type for the
-// field.
-func (d Data) WithSet(field string, val any) Data {
-	ret := d.copy()
+// importPath returns the path part of an import, which may have a name.
+func importPath(imp string) string {
+	_, path, _ := strings.Cut(imp, " ")
+	if "" == path {
+		return imp
+	}
+	return path
+}
 
-	/* Set the field. */
-	f := reflect.ValueOf(&ret).Elem().FieldByName(field)
-	if !f.IsValid() {
-		panic(fmt.Sprintf("Data has no field named %s", field))
+// importSpec returns an import, which may have a name, as it should appear in
+// an import block, sans indentation.
+func importSpec(imp string) string {
+	name, path, ok := strings.Cut(imp, " ")
+	if !ok {
+		return fmt.Sprintf("%q", imp)
 	}
-	f.Set(reflect.ValueOf(val))
+	return fmt.Sprintf("%s %q", name, path)
+}
 
-	return ret
+// sortImports sorts imports by path, as gofmt does.
+func sortImports(imps []string) {
+	sort.Slice(imps, func(i, j int) bool {
+		return importPath(imps[i]) < importPath(imps[j])
+	})
 }
@@ -5,7 +5,7 @@ package gencode
  * Tests for data.go
  * By J. Stuart McMurray
  * Created 20230418
- * Last Modified 20240419
+ * Last Modified 20261019
  */
 
 import (
@@ -44,6 +44,20 @@ func TestDataImportsBlock(t *testing.T) {
 	"github.com/example/002"
 	"github.com/test/001"
 	"golang.org/x/exp/maps"
+)`,
+	}, {
+		Have: []string{
+			"net/http",
+			"_ net/http/pprof",
+			"net",
+			"_ example.com/driver",
+		},
+		Want: `import (
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+
+	_ "example.com/driver"
 )`,
 	}} {
 		c := c /* :S */
@@ -63,6 +77,31 @@ func TestDataImportsBlock(t *testing.T) {
 	}
 }
 
+func TestDataEnvPrefix(t *testing.T) {
+	for have, want := range map[string]string{
+		"cooltool":  "COOLTOOL_",
+		"cool-tool": "COOL_TOOL_",
+		"cool.tool": "COOL_TOOL_",
+		"CoolTool2": "COOLTOOL2_",
+	} {
+		if got := (Data{Name: have}).EnvPrefix(); got != want {
+			t.Errorf("%q: got %q, want %q", have, got, want)
+		}
+	}
+}
+
+func TestDataFuncTestName(t *testing.T) {
+	for have, want := range map[string]string{
+		"Parse":     "Parse",
+		"parseLine": "ParseLine",
+		"ñandú":     "Ñandú",
+	} {
+		if got := (Data{FuncName: have}).FuncTestName(); got != want {
+			t.Errorf("%q: got %q, want %q", have, got, want)
+		}
+	}
+}
+
 func TestDataSetDefaults(t *testing.T) {
 	var data Data
 	data.SetDefaults()
@@ -85,3 +124,83 @@ func TestDataSetDefaults(t *testing.T) {
 		)
 	}
 }
+
+func TestDataSetDefaultsImplied(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		have  Data
+		check func(Data) bool
+	}{{
+		name:  "ordered_results",
+		have:  Data{Ordered: true},
+		check: func(d Data) bool { return d.Results },
+	}, {
+		name:  "progress_summarycount",
+		have:  Data{Progress: true},
+		check: func(d Data) bool { return d.SummaryCount },
+	}, {
+		name:  "interrupt_context",
+		have:  Data{Interrupt: true},
+		check: func(d Data) bool { return d.Context },
+	}} {
+		c := c /* :( */
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			c.have.SetDefaults()
+			if !c.check(c.have) {
+				t.Errorf("Implied field not set: %+v", c.have)
+			}
+		})
+	}
+}
+
+func TestDataMdocDate(t *testing.T) {
+	for have, want := range map[string]string{
+		"20261019":    "October 19, 2026",
+		"in the past": "in the past",
+	} {
+		if got := (Data{Today: have}).MdocDate(); got != want {
+			t.Errorf(
+				"MdocDate(%q): got %q, want %q",
+				have,
+				got,
+				want,
+			)
+		}
+	}
+}
+
+func TestDataBuildTargets(t *testing.T) {
+	got, err := Data{Platforms: []string{
+		"linux/amd64",
+		"",
+		" windows/arm64 ",
+	}}.BuildTargets()
+	if nil != err {
+		t.Fatalf("Error: %s", err)
+	}
+	want := []string{"linux-amd64", "windows-arm64.exe"}
+	if len(got) != len(want) {
+		t.Fatalf("Got %d targets, want %d", len(got), len(want))
+	}
+	for i, bt := range got {
+		if s := bt.Suffix(); s != want[i] {
+			t.Errorf("Target %d: got %s, want %s", i, s, want[i])
+		}
+	}
+
+	/* Invalid platforms shouldn't work. */
+	for _, p := range []string{
+		"linux",
+		"/amd64",
+		"linux/",
+		"a/b/c",
+		"linux/ amd64",
+	} {
+		if _, err := (Data{
+			Platforms: []string{p},
+		}).BuildTargets(); nil == err {
+			t.Errorf("No error for platform %q", p)
+		}
+	}
+}
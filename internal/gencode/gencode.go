@@ -6,19 +6,77 @@ package gencode
  * Generate toolskel code.
  * By J. Stuart McMurray
  * Created 20230425
- * Last Modified 20230425
+ * Last Modified 20261019
  */
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 	"text/template"
 )
 
 // DefaultTType is the default template to use.
 const DefaultTType = "simple"
 
+// DefaultMakeFlavor is the default flavor of makefile to generate.
+const DefaultMakeFlavor = "bsd"
+
+// MakeFlavors are the flavors of makefile we can generate.  The just flavor
+// generates a justfile.
+var MakeFlavors = []string{"bsd", "gnu", "just"}
+
+// companionTTypes are tool types which generate a single companion file for
+// another tool type, which is given in Data.ForType.  Each enables the file in
+// the other type's Data and names the file.
+var companionTTypes = map[string]struct {
+	enable   func(d *Data)
+	filename func(d Data) string
+}{
+	"test": {
+		enable:   func(d *Data) { d.Tests = true },
+		filename: func(d Data) string { return d.Name + "_test.go" },
+	},
+	"readme": {
+		enable:   func(d *Data) { d.Readme = true },
+		filename: func(Data) string { return "README.md" },
+	},
+	"manpage": {
+		enable:   func(d *Data) { d.Manpage = true },
+		filename: func(d Data) string { return d.Name + ".1" },
+	},
+	"completion": {
+		enable:   func(d *Data) { d.Completion = true },
+		filename: Data.CompletionFilename,
+	},
+}
+
+const (
+	// filenameTemplate is the subtemplate which names a type's main file.
+	filenameTemplate = "filename"
+
+	// extraFilesTemplate is the subtemplate which lists the files a type
+	// generates in addition to its main file, one per line, each as a
+	// filename and the name of the subtemplate which generates it.
+	extraFilesTemplate = "extraFiles"
+)
+
+// ErrMultipleFiles is returned by Generate for tool types which generate more
+// than one file.  Use GenerateDir instead.
+var ErrMultipleFiles = errors.New("multiple files generated")
+
+// File is a generated file.
+type File struct {
+	Name string /* Slash-separated, relative to the output directory. */
+	Body []byte
+}
+
 // Templates are the parsed templates
 var templates = make(map[string]*template.Template)
 
@@ -26,28 +84,179 @@ func init() {
 	mustParseTemplates()
 }
 
-// Generate does the code generation itself.
+// Generate does the code generation itself.  If the tool type generates more
+// than one file, Generate returns ErrMultipleFiles and writes nothing.
 func Generate(w io.Writer, tType string, data Data) error {
+	fs, err := GenerateFiles(tType, data)
+	if nil != err {
+		return err
+	}
+	if 1 != len(fs) {
+		return fmt.Errorf(
+			"tool type %q: %w (%d)",
+			tType,
+			ErrMultipleFiles,
+			len(fs),
+		)
+	}
+	_, err = w.Write(fs[0].Body)
+	return err
+}
+
+// GenerateFiles generates all of the files for a tool type.  The main file is
+// first.
+func GenerateFiles(tType string, data Data) ([]File, error) {
 	/* Make sure we have a template type. */
 	setDefault(&tType, DefaultTType)
 
 	/* Make sure all of the fields are filled. */
 	data.SetDefaults()
+	if !slices.Contains(MakeFlavors, data.MakeFlavor) {
+		return nil, fmt.Errorf(
+			"unknown make flavor %q",
+			data.MakeFlavor,
+		)
+	}
+
+	/* Companion files are generated by the type they accompany. */
+	if _, ok := companionTTypes[tType]; ok {
+		return generateCompanion(tType, data)
+	}
+	data.Type = tType
 
 	/* Get the template for this type, making sure we've parsed the
 	templates. */
 	tmpl, ok := templates[tType]
 	if !ok {
-		return fmt.Errorf("unknown tool type %q", tType)
+		return nil, fmt.Errorf("unknown tool type %q", tType)
+	}
+
+	/* Work out which files we'll need. */
+	fn, err := executeString(tmpl, filenameTemplate, data)
+	if nil != err {
+		return nil, fmt.Errorf("getting filename: %w", err)
+	}
+	efs, err := executeString(tmpl, extraFilesTemplate, data)
+	if nil != err {
+		return nil, fmt.Errorf("getting extra files: %w", err)
 	}
 
 	/* Emit boilerplate. */
-	return tmpl.Execute(w, data)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); nil != err {
+		return nil, err
+	}
+	fs := []File{{Name: fn, Body: buf.Bytes()}}
+	for _, l := range strings.Split(efs, "\n") {
+		/* Each line should be a filename and a template name. */
+		parts := strings.Fields(l)
+		if 0 == len(parts) {
+			continue
+		} else if 2 != len(parts) {
+			return nil, fmt.Errorf("invalid extra file line %q", l)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(
+			&buf,
+			parts[1],
+			data,
+		); nil != err {
+			return nil, fmt.Errorf(
+				"generating %s: %w",
+				parts[0],
+				err,
+			)
+		}
+		fs = append(fs, File{Name: parts[0], Body: buf.Bytes()})
+	}
+
+	return fs, nil
+}
+
+// generateCompanion generates only the file the companion type tType
+// generates for data.ForType.
+func generateCompanion(tType string, data Data) ([]File, error) {
+	if _, ok := companionTTypes[data.ForType]; ok {
+		return nil, fmt.Errorf(
+			"can't generate %s for %s",
+			tType,
+			data.ForType,
+		)
+	}
+	ct := companionTTypes[tType]
+	ct.enable(&data)
+	fs, err := GenerateFiles(data.ForType, data)
+	if nil != err {
+		return nil, err
+	}
+	fn := ct.filename(data)
+	for _, f := range fs {
+		if fn == f.Name {
+			return []File{f}, nil
+		}
+	}
+	return nil, fmt.Errorf("tool type %q has no %s", data.ForType, tType)
+}
+
+// GenerateDir generates all of the files for a tool type in the directory dir,
+// which will be created if it doesn't exist.  Existing files will not be
+// overwritten.  data.InDir is set to true.
+func GenerateDir(dir, tType string, data Data) error {
+	/* Generate everything first, so we don't leave half a directory if
+	something's wrong with a template. */
+	data.InDir = true
+	fs, err := GenerateFiles(tType, data)
+	if nil != err {
+		return err
+	}
+
+	/* Write it all out. */
+	for _, f := range fs {
+		if err := writeNewFile(
+			filepath.Join(dir, filepath.FromSlash(f.Name)),
+			f.Body,
+		); nil != err {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeNewFile writes b to the file named fn, which must not already exist.
+// Any missing parent directories are created.
+func writeNewFile(fn string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); nil != err {
+		return err
+	}
+	f, err := os.OpenFile(fn, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if nil != err {
+		return err
+	}
+	if _, err := f.Write(b); nil != err {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", fn, err)
+	}
+	return f.Close()
+}
+
+// executeString executes the named subtemplate of t and returns its output,
+// trimmed of leading and trailing whitespace.
+func executeString(
+	t *template.Template,
+	name string,
+	data Data,
+) (string, error) {
+	var sb strings.Builder
+	if err := t.ExecuteTemplate(&sb, name, data); nil != err {
+		return "", err
+	}
+	return strings.TrimSpace(sb.String()), nil
 }
 
 // setDefault sets *p to T if *p is the zero value for its type.  If p is nil,
 // SetDefault panics.
-func setDefault[T string | map[string]struct{}](p *T, def T) {
+func setDefault[T string | []string | map[string]struct{}](p *T, def T) {
 	/* Doesn't work with nil. */
 	if nil == p {
 		panic("setDefault: nil pointer")
@@ -0,0 +1,160 @@
+package gencode
+
+/*
+ * gencode_build_test.go
+ * Build tests for gencode.go's known-goods
+ * By J. Stuart McMurray
+ * Created 20261019
+ * Last Modified 20261019
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWantBuild tests that the test cases' known-goods actually build.  It
+// takes a while, so is skipped with -short.
+func TestWantBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Not building known-goods with -short")
+	}
+	des, err := testWants.ReadDir(testWantsDir)
+	if nil != err {
+		t.Fatalf("Error reading embedded FS: %s", err)
+	}
+
+	for _, de := range des {
+		de := de /* D: */
+		t.Run(de.Name(), func(t *testing.T) {
+			t.Parallel()
+			/* Put the file or files in a temporary directory. */
+			efn := filepath.Join(testWantsDir, de.Name())
+			files, err := wantBuildFiles(de, efn)
+			if nil != err {
+				t.Errorf("Error reading %s: %s", efn, err)
+				return
+			}
+			td := t.TempDir()
+			var haveGo, haveCode, haveMain, haveTests bool
+			for n, b := range files {
+				fn := filepath.Join(td, filepath.FromSlash(n))
+				err := os.MkdirAll(filepath.Dir(fn), 0770)
+				if nil == err {
+					err = os.WriteFile(fn, b, 0660)
+				}
+				if nil != err {
+					t.Errorf(
+						"Error writing %s: %s",
+						fn,
+						err,
+					)
+					return
+				}
+				if !strings.HasSuffix(n, ".go") {
+					continue
+				}
+				haveGo = true
+				if bytes.HasPrefix(b, []byte("// Program ")) {
+					haveMain = true
+				}
+				if strings.HasSuffix(n, "_test.go") {
+					haveTests = true
+				} else {
+					haveCode = true
+				}
+			}
+
+			/* If we've got Go code, try to build it. */
+			if !haveGo {
+				return
+			}
+			if _, err := combinedOutput(
+				t,
+				td,
+				"go mod init tstest",
+			); nil != err {
+				t.Errorf("Error adding go.mod: %s", err)
+				return
+			}
+			var cmds []string
+			if haveMain {
+				cmds = append(cmds, "go run . -h")
+			}
+			/* Tests need code to test. */
+			if haveTests && haveCode {
+				cmds = append(
+					cmds,
+					"go vet ./...",
+					"go test ./...",
+				)
+			}
+			for _, cmd := range cmds {
+				_, err := combinedOutput(t, td, cmd)
+				if nil != err {
+					t.Errorf(
+						"%s failed with error: %s",
+						cmd,
+						err,
+					)
+					return
+				}
+			}
+		})
+	}
+}
+
+// wantBuildFiles returns the file or, if de is a directory, files named by de
+// and found at path in testWants.
+func wantBuildFiles(de fs.DirEntry, path string) (map[string][]byte, error) {
+	if de.IsDir() {
+		return readWantFiles(path)
+	}
+	b, err := testWants.ReadFile(path)
+	if nil != err {
+		return nil, err
+	}
+	return map[string][]byte{de.Name(): b}, nil
+}
+
+// combinedOutputError is returned by combinedOutput when the underlying
+// exec.Cmd.CombinedOutput returns an error.
+type combinedOutputError struct {
+	Output []byte
+	Err    error
+}
+
+// Unwrap returns the underlying error but no output.
+func (err combinedOutputError) Unwrap() error { return err.Err }
+
+// Error implements the error interface.
+func (err combinedOutputError) Error() string {
+	if 0 == len(err.Output) {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s\nOutput:\n%s", err.Err, err.Output)
+}
+
+// combinedOutput returns the output of running the command in a shell in the
+// given directory, plus any errors encountered.  If exec.Cmd.CombinedOutput
+// returns an error, combinedOutput returns a combinedOutputError.
+func combinedOutput(t *testing.T, dir, cmd string) ([]byte, error) {
+	t.Helper()
+	if 0 == len(cmd) {
+		return nil, fmt.Errorf("empty command")
+	}
+	/* Yeah, Windows.  PRs welcome. */
+	c := exec.Command("/bin/sh", "-c", cmd)
+	c.Dir = dir
+	o, err := c.CombinedOutput()
+	if nil != err {
+		return nil, combinedOutputError{Output: o, Err: err}
+	}
+	return o, nil
+}
@@ -7,7 +7,7 @@ package gencode
  * GoImports Tests for gencode.go
  * By J. Stuart McMurray
  * Created 20230415
- * Last Modified 20230427
+ * Last Modified 20261019
  */
 
 import (
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -50,31 +51,47 @@ func TestMainGoImports(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			t.Parallel()
 			/* Generate the code with this config. */
-			var buf bytes.Buffer
-			if err := Generate(&buf, c.tType, c.data); nil != err {
+			files, err := GenerateFiles(c.tType, c.data)
+			if nil != err {
 				t.Errorf("generating code: %s", err)
 				return
 			}
-			sCode := buf.Bytes()
-			/* Run it through goimports. */
-			iCode, err := runGoImports(sCode)
-			if nil != err {
-				if 0 == len(iCode) {
-					t.Errorf(
-						"goimports returned error: %s",
-						err,
-					)
-					return
+			for _, f := range files {
+				if strings.HasSuffix(f.Name, ".go") {
+					checkGoImports(t, runGoImports, f)
 				}
-				t.Errorf(
-					"goimports returned error: %s\n%s",
-					err,
-					iCode,
-				)
-				return
 			}
-			/* Tell someone if it's not the same. */
-			errorIfDiff(t, sCode, iCode, "generated", "goimported")
 		})
 	}
 }
+
+// checkGoImports checks that f is unchanged by goimports, which is called via
+// runGoImports.
+func checkGoImports(
+	t *testing.T,
+	runGoImports func([]byte) ([]byte, error),
+	f File,
+) {
+	t.Helper()
+	/* Run it through goimports. */
+	iCode, err := runGoImports(f.Body)
+	if nil != err {
+		if 0 == len(iCode) {
+			t.Errorf(
+				"goimports returned error on %s: %s",
+				f.Name,
+				err,
+			)
+			return
+		}
+		t.Errorf(
+			"goimports returned error on %s: %s\n%s",
+			f.Name,
+			err,
+			iCode,
+		)
+		return
+	}
+	/* Tell someone if it's not the same. */
+	errorIfDiff(t, f.Body, iCode, "generated", "goimported")
+}
@@ -5,15 +5,15 @@ package gencode
  * Tests for gencode.go
  * By J. Stuart McMurray
  * Created 20230415
- * Last Modified 20240419
+ * Last Modified 20261019
  */
 
 import (
 	"bytes"
 	"embed"
 	"fmt"
+	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -21,7 +21,7 @@ import (
 
 // testWants contains the contents of the tests/ directory
 //
-//go:embed _tests
+//go:embed all:_tests
 var testWants embed.FS
 
 // testWantsDir is the directory in testWants with the files for TestCases.
@@ -29,12 +29,14 @@ const testWantsDir = "_tests"
 
 // TestCases are common test cases for various tests.  Each test should have
 // a corresponding file in tests/ named after .name, with /'s replaced by
-// _'s and a .go suffix.
+// _'s and a .go suffix.  Tool types which generate multiple files should
+// instead have a directory with the generated files.
 var TestCases = []struct {
-	name  string
-	tType string /* Template name, less template/ and .tmpl */
-	data  Data
-	want  []byte
+	name      string
+	tType     string /* Template name, less template/ and .tmpl */
+	data      Data
+	want      []byte
+	wantFiles map[string][]byte /* For multiple files. */
 }{{
 	name: "simple.go",
 }, {
@@ -58,6 +60,75 @@ var TestCases = []struct {
 		SummaryCount: true,
 		Verbose:      true,
 	},
+}, {
+	name: "simple/progress.go",
+	data: Data{
+		Progress: true,
+	},
+}, {
+	name: "simple/interrupt.go",
+	data: Data{
+		Interrupt: true,
+	},
+}, {
+	name: "simple/slog.go",
+	data: Data{
+		Slog: true,
+	},
+}, {
+	name: "simple/slogverbosetaglog.go",
+	data: Data{
+		Slog:         true,
+		Verbose:      true,
+		TagLog:       true,
+		SummaryCount: true,
+	},
+}, {
+	name: "simple/context.go",
+	data: Data{
+		Context: true,
+	},
+}, {
+	name: "simple/version.go",
+	data: Data{
+		Version: true,
+	},
+}, {
+	name: "simple/versionslogverbose.go",
+	data: Data{
+		Version: true,
+		Slog:    true,
+		Verbose: true,
+	},
+}, {
+	name: "simple/envflags.go",
+	data: Data{
+		EnvFlags: true,
+	},
+}, {
+	name: "simple/config.go",
+	data: Data{
+		Config: true,
+	},
+}, {
+	name: "simple/configslogprogress.go",
+	data: Data{
+		Config:   true,
+		Slog:     true,
+		Verbose:  true,
+		Progress: true,
+	},
+}, {
+	name: "simple/configenvflags.go",
+	data: Data{
+		Config:   true,
+		EnvFlags: true,
+	},
+}, {
+	name: "simple/profiling.go",
+	data: Data{
+		Profiling: true,
+	},
 }, {
 	name:  "parallel.go",
 	tType: "parallel",
@@ -74,14 +145,425 @@ var TestCases = []struct {
 		Verbose: true,
 	},
 }, {
-	name: "library.go",
+	name:  "parallel/summarycountverbose.go",
+	tType: "parallel",
+	data: Data{
+		SummaryCount: true,
+		Verbose:      true,
+	},
+}, {
+	name:  "parallel/stream.go",
+	tType: "parallel",
+	data: Data{
+		Stream: true,
+	},
+}, {
+	name:  "parallel/streamsummarycount.go",
+	tType: "parallel",
+	data: Data{
+		Stream:       true,
+		SummaryCount: true,
+	},
+}, {
+	name:  "parallel/results.go",
+	tType: "parallel",
+	data: Data{
+		Results: true,
+	},
+}, {
+	name:  "parallel/ordered.go",
+	tType: "parallel",
+	data: Data{
+		Ordered: true,
+	},
+}, {
+	name:  "parallel/streamordered.go",
+	tType: "parallel",
+	data: Data{
+		Stream:       true,
+		Ordered:      true,
+		SummaryCount: true,
+	},
+}, {
+	name:  "parallel/ratelimit.go",
+	tType: "parallel",
+	data: Data{
+		RateLimit: true,
+	},
+}, {
+	name:  "parallel/tasktimeout.go",
+	tType: "parallel",
+	data: Data{
+		TaskTimeout:  true,
+		SummaryCount: true,
+	},
+}, {
+	name:  "parallel/ratelimittasktimeoutresults.go",
+	tType: "parallel",
+	data: Data{
+		RateLimit:   true,
+		TaskTimeout: true,
+		Results:     true,
+	},
+}, {
+	name:  "parallel/retries.go",
+	tType: "parallel",
+	data: Data{
+		Retries: true,
+	},
+}, {
+	name:  "parallel/retriesverbose.go",
+	tType: "parallel",
+	data: Data{
+		Retries: true,
+		Verbose: true,
+		Results: true,
+	},
+}, {
+	name:  "parallel/retriestasktimeoutratelimit.go",
+	tType: "parallel",
+	data: Data{
+		Retries:      true,
+		TaskTimeout:  true,
+		RateLimit:    true,
+		SummaryCount: true,
+	},
+}, {
+	name:  "parallel/checkpoint.go",
+	tType: "parallel",
+	data: Data{
+		Checkpoint: true,
+	},
+}, {
+	name:  "parallel/checkpointstreamordered.go",
+	tType: "parallel",
+	data: Data{
+		Checkpoint:   true,
+		Stream:       true,
+		Ordered:      true,
+		SummaryCount: true,
+		TaskTimeout:  true,
+	},
+}, {
+	name:  "parallel/progress.go",
+	tType: "parallel",
+	data: Data{
+		Progress: true,
+	},
+}, {
+	name:  "parallel/progresscheckpoint.go",
+	tType: "parallel",
+	data: Data{
+		Progress:   true,
+		Checkpoint: true,
+		Verbose:    true,
+	},
+}, {
+	name:  "parallel/progresscheckpointstream.go",
+	tType: "parallel",
+	data: Data{
+		Progress:   true,
+		Checkpoint: true,
+		Stream:     true,
+	},
+}, {
+	name:  "parallel/interrupt.go",
+	tType: "parallel",
+	data: Data{
+		Interrupt: true,
+	},
+}, {
+	name:  "parallel/interruptstreamordered.go",
+	tType: "parallel",
+	data: Data{
+		Interrupt:    true,
+		Stream:       true,
+		Ordered:      true,
+		SummaryCount: true,
+	},
+}, {
+	name:  "parallel/interruptratelimitretries.go",
+	tType: "parallel",
+	data: Data{
+		Interrupt: true,
+		RateLimit: true,
+		Retries:   true,
+		Results:   true,
+	},
+}, {
+	name:  "parallel/slog.go",
+	tType: "parallel",
+	data: Data{
+		Slog:         true,
+		SummaryCount: true,
+		TaskTimeout:  true,
+		Interrupt:    true,
+	},
+}, {
+	name:  "parallel/slogcheckpointretriesstream.go",
+	tType: "parallel",
+	data: Data{
+		Slog:       true,
+		Checkpoint: true,
+		Retries:    true,
+		Stream:     true,
+	},
+}, {
+	name:  "parallel/context.go",
+	tType: "parallel",
+	data: Data{
+		Context: true,
+	},
+}, {
+	name:  "parallel/contextstreamretriestasktimeout.go",
+	tType: "parallel",
+	data: Data{
+		Context:     true,
+		Stream:      true,
+		Results:     true,
+		Retries:     true,
+		TaskTimeout: true,
+	},
+}, {
+	name:  "parallel/version.go",
+	tType: "parallel",
+	data: Data{
+		Version: true,
+		Context: true,
+	},
+}, {
+	name:  "parallel/envflags.go",
+	tType: "parallel",
+	data: Data{
+		EnvFlags:    true,
+		Verbose:     true,
+		TaskTimeout: true,
+	},
+}, {
+	name:  "parallel/config.go",
+	tType: "parallel",
+	data: Data{
+		Config: true,
+	},
+}, {
+	name:  "parallel/configall.go",
+	tType: "parallel",
+	data: Data{
+		Config:      true,
+		RateLimit:   true,
+		TaskTimeout: true,
+		Retries:     true,
+		Checkpoint:  true,
+	},
+}, {
+	name:  "parallel/profiling.go",
+	tType: "parallel",
+	data: Data{
+		Profiling:    true,
+		SummaryCount: true,
+	},
+}, {
+	name:  "periodic.go",
+	tType: "periodic",
+}, {
+	name:  "periodic/summarycount.go",
+	tType: "periodic",
+	data: Data{
+		SummaryCount: true,
+	},
+}, {
+	name:  "periodic/verbose.go",
+	tType: "periodic",
+	data: Data{
+		Verbose: true,
+	},
+}, {
+	name:  "periodic/progress.go",
+	tType: "periodic",
+	data: Data{
+		Progress: true,
+	},
+}, {
+	name:  "periodic/interrupt.go",
+	tType: "periodic",
+	data: Data{
+		Interrupt:    true,
+		SummaryCount: true,
+	},
+}, {
+	name:  "periodic/context.go",
+	tType: "periodic",
+	data: Data{
+		Context: true,
+	},
+}, {
+	name:  "periodic/slog.go",
+	tType: "periodic",
 	data: Data{
-		Name: "main", /* For testing. */
+		Slog:      true,
+		Interrupt: true,
 	},
+}, {
+	name:  "periodic/config.go",
+	tType: "periodic",
+	data: Data{
+		Config: true,
+	},
+}, {
+	name:  "library",
 	tType: "library",
+}, {
+	name: "simple/tests",
+	data: Data{
+		Tests: true,
+	},
+}, {
+	name:  "parallel/tests",
+	tType: "parallel",
+	data: Data{
+		Tests:   true,
+		Results: true,
+		Context: true,
+	},
+}, {
+	name:  "parallel/readme",
+	tType: "parallel",
+	data: Data{
+		Readme:      true,
+		Tests:       true,
+		EnvFlags:    true,
+		TaskTimeout: true,
+	},
+}, {
+	name: "simple/readme",
+	data: Data{Readme: true},
+}, {
+	name:  "periodic/manpage",
+	tType: "periodic",
+	data: Data{
+		Manpage:  true,
+		EnvFlags: true,
+		Version:  true,
+	},
+}, {
+	name:  "periodic/tests",
+	tType: "periodic",
+	data: Data{
+		Tests:        true,
+		SummaryCount: true,
+	},
+}, {
+	name:  "fuzz",
+	tType: "fuzz",
+}, {
+	name:  "fuzz/parseline",
+	tType: "fuzz",
+	data: Data{
+		FuncName: "parseLine",
+		Package:  "cooltool",
+	},
+}, {
+	name:  "test/parallel_test.go",
+	tType: "test",
+	data: Data{
+		ForType: "parallel",
+		Results: true,
+		Context: true,
+	},
+}, {
+	name:  "readme/simple.md",
+	tType: "readme",
+}, {
+	name:  "manpage/periodic.1",
+	tType: "manpage",
+	data: Data{
+		ForType:  "periodic",
+		EnvFlags: true,
+	},
+}, {
+	name:  "completion/periodic.bash",
+	tType: "completion",
+	data: Data{
+		ForType:  "periodic",
+		EnvFlags: true,
+	},
+}, {
+	name:  "completion/periodic.zsh",
+	tType: "completion",
+	data: Data{
+		ForType: "periodic",
+		Shell:   "zsh",
+	},
+}, {
+	name:  "completion/periodic.fish",
+	tType: "completion",
+	data: Data{
+		ForType: "periodic",
+		Shell:   "fish",
+	},
+}, {
+	name:  "completion/parallel.bash",
+	tType: "completion",
+	data: Data{
+		ForType:     "parallel",
+		TaskTimeout: true,
+		Checkpoint:  true,
+	},
+}, {
+	name:  "Dockerfile",
+	tType: "dockerfile",
+	data:  Data{Version: true},
+}, {
+	name:  "dockerfile/dir",
+	tType: "dockerfile",
+	data:  Data{InDir: true},
+}, {
+	name:  "systemd.service",
+	tType: "systemd",
+}, {
+	name:  "systemd/options.service",
+	tType: "systemd",
+	data: Data{
+		ServiceUser: "daemon",
+		Restart:     "no",
+		EnvFile:     "/etc/cooltool.env",
+	},
+}, {
+	name:  "rcd",
+	tType: "rcd",
+}, {
+	name:  "rcd/options",
+	tType: "rcd",
+	data: Data{
+		ServiceUser: "daemon",
+		Restart:     "no",
+		EnvFile:     "/etc/cooltool.env",
+	},
 }, {
 	name:  "Makefile",
 	tType: "makefile",
+}, {
+	name:  "Makefile.gnu",
+	tType: "makefile",
+	data:  Data{MakeFlavor: "gnu"},
+}, {
+	name:  "makefile/noplatforms",
+	tType: "makefile",
+	data:  Data{Platforms: []string{""}},
+}, {
+	name:  "justfile",
+	tType: "makefile",
+	data: Data{
+		MakeFlavor: "just",
+		Platforms:  []string{"linux/amd64", "windows/arm64"},
+	},
+}, {
+	name:  "justfile/noplatforms",
+	tType: "makefile",
+	data: Data{
+		MakeFlavor: "just",
+		Platforms:  []string{""},
+	},
 }}
 
 // init populates TestCases's data fields.
@@ -98,6 +580,18 @@ func init() {
 			testWantsDir,
 			strings.Replace(c.name, "/", "_", -1),
 		)
+		if fi, err := fs.Stat(testWants, fn); nil == err && fi.IsDir() {
+			c.wantFiles, err = readWantFiles(fn)
+			if nil != err {
+				panic(fmt.Sprintf(
+					"reading test files in %s: %s",
+					fn,
+					err,
+				))
+			}
+			TestCases[i] = c
+			continue
+		}
 		c.want, err = testWants.ReadFile(fn)
 		if nil != err {
 			panic(fmt.Sprintf("reading test file %s: %s", fn, err))
@@ -107,10 +601,35 @@ func init() {
 	}
 }
 
+// readWantFiles reads the files in and under the named directory in
+// testWants.  The returned map's keys are slash-separated paths relative to
+// dir.
+func readWantFiles(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if err := fs.WalkDir(testWants, dir, func(
+		path string,
+		d fs.DirEntry,
+		err error,
+	) error {
+		if nil != err || d.IsDir() {
+			return err
+		}
+		b, err := testWants.ReadFile(path)
+		if nil != err {
+			return err
+		}
+		files[strings.TrimPrefix(path, dir+"/")] = b
+		return nil
+	}); nil != err {
+		return nil, err
+	}
+	return files, nil
+}
+
 func TestGenCode(t *testing.T) {
 	for _, c := range TestCases {
 		/* If there's no known good for this one, skip it. */
-		if 0 == len(c.want) {
+		if 0 == len(c.want) && 0 == len(c.wantFiles) {
 			continue
 		}
 		t.Run(c.name, func(t *testing.T) {
@@ -122,6 +641,15 @@ func TestGenCode(t *testing.T) {
 			if "" == c.data.Name {
 				c.data.Name = defaultProgramName
 			}
+			if nil != c.wantFiles {
+				checkGenerateFiles(
+					t,
+					c.tType,
+					c.data,
+					c.wantFiles,
+				)
+				return
+			}
 			if err := Generate(&buf, c.tType, c.data); nil != err {
 				t.Errorf("error: %s", err)
 				return
@@ -131,95 +659,102 @@ func TestGenCode(t *testing.T) {
 	}
 }
 
-// TestWantBuild tests that the test cases' known-goods actually build.
-func TestWantBuild(t *testing.T) {
-	des, err := testWants.ReadDir(testWantsDir)
-	if nil != err {
-		t.Fatalf("Error reading embedded FS: %s", err)
+func TestGenerateDir(t *testing.T) {
+	d := filepath.Join(t.TempDir(), "lib")
+	if err := GenerateDir(d, "library", Data{Name: "lib"}); nil != err {
+		t.Fatalf("Error generating library: %s", err)
 	}
 
+	/* Should have all the files. */
+	des, err := os.ReadDir(d)
+	if nil != err {
+		t.Fatalf("Error reading %s: %s", d, err)
+	}
+	var got []string
 	for _, de := range des {
-		de := de /* D: */
-		t.Run(de.Name(), func(t *testing.T) {
-			t.Parallel()
-			if de.IsDir() {
-				t.Errorf("Got a directory, expected a file")
-				return
-			}
-			efn := filepath.Join(testWantsDir, de.Name())
-			b, err := testWants.ReadFile(efn)
-			if nil != err {
-				t.Errorf("Error reading file %s: %s", efn, err)
-				return
-			}
-			td := t.TempDir()
-			fn := filepath.Join(td, de.Name())
-			if err := os.WriteFile(fn, b, 0660); nil != err {
-				t.Errorf("Error writing to %s: %s", fn, err)
-				return
-			}
+		got = append(got, de.Name())
+	}
+	want := "doc.go example_test.go lib.go lib_test.go"
+	if s := strings.Join(got, " "); s != want {
+		t.Errorf("Incorrect files\n got: %s\nwant: %s", s, want)
+	}
 
-			/* If we've got a Go file, try to build it. */
-			if strings.HasSuffix(de.Name(), ".go") {
-				if _, err := combinedOutput(
-					t,
-					td,
-					"go mod init tstest",
-				); nil != err {
-					t.Errorf(
-						"Error adding go.mod: %s",
-						err,
-					)
-					return
-				}
-				if _, err := combinedOutput(
-					t,
-					td,
-					"go run . -h",
-				); nil != err {
-					t.Errorf(
-						"Build failed with error: %s",
-						err,
-					)
-					return
-				}
-			}
-		})
+	/* Shouldn't overwrite anything. */
+	if err := GenerateDir(d, "library", Data{Name: "lib"}); nil == err {
+		t.Errorf("No error when files exist")
 	}
 }
 
-// combinedOutputError is returned by combinedOutput when the underlying
-// exec.Cmd.CombinedOutput returns an error.
-type combinedOutputError struct {
-	Output []byte
-	Err    error
+func TestGenerateCompanionTypes(t *testing.T) {
+	/* Not everything has tests, READMEs, and so on. */
+	for tType := range companionTTypes {
+		if err := Generate(&bytes.Buffer{}, tType, Data{
+			ForType: "makefile",
+		}); nil == err {
+			t.Errorf("No error generating makefile %s", tType)
+		}
+	}
 }
 
-// Unwrap returns the underlying error but no output.
-func (err combinedOutputError) Unwrap() error { return err.Err }
+func TestGenerateMakeFlavor(t *testing.T) {
+	for flavor, want := range map[string]string{
+		"bsd":  "Makefile",
+		"gnu":  "Makefile",
+		"just": "justfile",
+	} {
+		fs, err := GenerateFiles("makefile", Data{MakeFlavor: flavor})
+		if nil != err {
+			t.Errorf(
+				"Error generating %s makefile: %s",
+				flavor,
+				err,
+			)
+			continue
+		}
+		if got := fs[0].Name; got != want {
+			t.Errorf(
+				"Incorrect %s filename: got %s, want %s",
+				flavor,
+				got,
+				want,
+			)
+		}
+	}
 
-// Error implements the error interface.
-func (err combinedOutputError) Error() string {
-	if 0 == len(err.Output) {
-		return err.Error()
+	/* Unknown flavors shouldn't work. */
+	if _, err := GenerateFiles("makefile", Data{
+		MakeFlavor: "cmake",
+	}); nil == err {
+		t.Errorf("No error for unknown make flavor")
 	}
-	return fmt.Sprintf("%s\nOutput:\n%s", err.Err, err.Output)
 }
 
-// combinedOutput returns the output of running the command in a shell in the
-// given directory, plus any errors encountered.  If exec.Cmd.CombinedOutput
-// returns an error, combinedOutput returns a combinedOutputError.
-func combinedOutput(t *testing.T, dir, cmd string) ([]byte, error) {
+// checkGenerateFiles checks that GenerateFiles generates the wanted files.
+func checkGenerateFiles(
+	t *testing.T,
+	tType string,
+	data Data,
+	want map[string][]byte,
+) {
 	t.Helper()
-	if 0 == len(cmd) {
-		return nil, fmt.Errorf("empty command")
-	}
-	/* Yeah, Windows.  PRs welcome. */
-	c := exec.Command("/bin/sh", "-c", cmd)
-	c.Dir = dir
-	o, err := c.CombinedOutput()
+	files, err := GenerateFiles(tType, data)
 	if nil != err {
-		return nil, combinedOutputError{Output: o, Err: err}
+		t.Errorf("error: %s", err)
+		return
+	}
+	got := make(map[string]bool)
+	for _, f := range files {
+		got[f.Name] = true
+		w, ok := want[f.Name]
+		if !ok {
+			t.Errorf("Unexpected file %s", f.Name)
+			continue
+		}
+		errorIfDiff(t, f.Body, w, "got "+f.Name, "want "+f.Name)
+	}
+	for fn := range want {
+		if !got[fn] {
+			t.Errorf("Missing file %s", fn)
+		}
 	}
-	return o, nil
 }
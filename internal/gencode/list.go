@@ -5,7 +5,7 @@ package gencode
  * List template types
  * By J. Stuart McMurray
  * Created 20230418
- * Last Modified 20230425
+ * Last Modified 20261019
  */
 
 import (
@@ -22,11 +22,17 @@ import (
 // descriptionTemplate is the subtemplate name which prints a description.
 const descriptionTemplate = "description"
 
+// TypeNames returns the names of the template types, sorted.
+func TypeNames() []string {
+	tns := maps.Keys(templates)
+	sort.Strings(tns)
+	return tns
+}
+
 // ListTypes prints template types as a table to stdout.
 func ListTypes() {
 	/* Work out which templates we have available. */
-	tns := maps.Keys(templates)
-	sort.Strings(tns)
+	tns := TypeNames()
 
 	/* Output will be nice and tabley. */
 	tw := tabwriter.NewWriter(os.Stdout, 2, 8, 1, ' ', 0)
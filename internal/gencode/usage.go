@@ -0,0 +1,309 @@
+package gencode
+
+/*
+ * usage.go
+ * Work out a generated tool's usage message and flags
+ * By J. Stuart McMurray
+ * Created 20261019
+ * Last Modified 20261019
+ */
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeUnits are the time package's durations, for working out flags' default
+// durations.
+var timeUnits = map[string]time.Duration{
+	"Nanosecond":  time.Nanosecond,
+	"Microsecond": time.Microsecond,
+	"Millisecond": time.Millisecond,
+	"Second":      time.Second,
+	"Minute":      time.Minute,
+	"Hour":        time.Hour,
+}
+
+// UsageFlag describes one of a generated tool's flags.
+type UsageFlag struct {
+	Name    string /* Flag name, without the -. */
+	Arg     string /* Argument name, empty for boolean flags. */
+	Usage   string /* Usage, without backquotes. */
+	Default string /* Default value, empty if it's the zero value. */
+	EnvVar  string /* Environment variable, if d.EnvFlags. */
+}
+
+// Usage returns the usage message printed by d.Type's tool's -h, worked out
+// from the flags in its generated code.  If d.Type doesn't generate a program,
+// Usage returns the empty string.
+func (d Data) Usage() (string, error) {
+	flags, err := d.flagSet(d.EnvFlags)
+	if nil != err || nil == flags {
+		return "", err
+	}
+
+	/* Roll it all into a usage message. */
+	var sb strings.Builder
+	fmt.Fprintf(
+		&sb,
+		"Usage: %s [options]\n\n%s\n\nOptions:\n",
+		d.Name,
+		d.Description,
+	)
+	flags.SetOutput(&sb)
+	flags.PrintDefaults()
+
+	return sb.String(), nil
+}
+
+// UsageFlags returns the flags of d.Type's tool, sorted by name.  If d.Type
+// doesn't generate a program, UsageFlags returns nil.
+func (d Data) UsageFlags() ([]UsageFlag, error) {
+	flags, err := d.flagSet(false)
+	if nil != err || nil == flags {
+		return nil, err
+	}
+	ufs := usageFlags(flags)
+	if d.EnvFlags {
+		for i, uf := range ufs {
+			ufs[i].EnvVar = d.envVar(uf.Name)
+		}
+	}
+	return ufs, nil
+}
+
+// usageFlags returns the flags in fs, sorted by name, without environment
+// variables.
+func usageFlags(fs *flag.FlagSet) []UsageFlag {
+	var ufs []UsageFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		uf := UsageFlag{Name: f.Name, Default: f.DefValue}
+		uf.Arg, uf.Usage = flag.UnquoteUsage(f)
+		switch f.DefValue {
+		case "", "0", "0s", "false":
+			uf.Default = ""
+		}
+		ufs = append(ufs, uf)
+	})
+	return ufs
+}
+
+// envVar returns the environment variable which may set the flag named name.
+func (d Data) envVar(name string) string {
+	n := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	return d.EnvPrefix() + n
+}
+
+// flagSet returns a FlagSet with the flags in d.Type's generated code.  If
+// withEnv is true, the environment variables which may also set the flags are
+// added to the flags' usage.  If d.Type doesn't generate a program, flagSet
+// returns nil.
+func (d Data) flagSet(withEnv bool) (*flag.FlagSet, error) {
+	/* Generate just the program itself. */
+	d.Tests = false
+	d.Readme = false
+	d.Manpage = false
+	d.Completion = false
+	fs, err := GenerateFiles(d.Type, d)
+	if nil != err {
+		return nil, fmt.Errorf("generating %s: %w", d.Type, err)
+	}
+	if !bytes.HasPrefix(fs[0].Body, []byte("// Program ")) {
+		return nil, nil
+	}
+
+	/* Find the flags. */
+	f, err := parser.ParseFile(
+		token.NewFileSet(),
+		fs[0].Name,
+		fs[0].Body,
+		0,
+	)
+	if nil != err {
+		return nil, fmt.Errorf("parsing %s: %w", fs[0].Name, err)
+	}
+	flags := flag.NewFlagSet(d.Name, flag.ContinueOnError)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if nil != err {
+			return false
+		}
+		ce, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		err = d.addUsageFlag(flags, ce, withEnv)
+		return true
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	return flags, nil
+}
+
+// addUsageFlag adds the flag defined by ce, if it defines one, to flags.  If
+// withEnv is true, the environment variable which may also set the flag is
+// added to the flag's usage.
+func (d Data) addUsageFlag(
+	flags *flag.FlagSet,
+	ce *ast.CallExpr,
+	withEnv bool,
+) error {
+	/* Only care about flag.Whatever(name, default, usage). */
+	se, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok || 3 != len(ce.Args) {
+		return nil
+	}
+	if id, ok := se.X.(*ast.Ident); !ok || "flag" != id.Name {
+		return nil
+	}
+
+	/* Get the name and usage. */
+	name, err := evalString(ce.Args[0])
+	if nil != err {
+		return fmt.Errorf("flag name: %w", err)
+	}
+	usage, err := evalString(ce.Args[2])
+	if nil != err {
+		return fmt.Errorf("usage for -%s: %w", name, err)
+	}
+	if withEnv {
+		usage += " [$" + d.envVar(name) + "]"
+	}
+
+	/* Add the flag with its default. */
+	def := ce.Args[1]
+	switch se.Sel.Name {
+	case "Bool":
+		var v bool
+		if v, err = evalBool(def); nil == err {
+			flags.Bool(name, v, usage)
+		}
+	case "Duration":
+		var v time.Duration
+		if v, err = evalDuration(def); nil == err {
+			flags.Duration(name, v, usage)
+		}
+	case "Float64":
+		var v float64
+		if v, err = evalFloat(def); nil == err {
+			flags.Float64(name, v, usage)
+		}
+	case "Int", "Uint", "Uint64":
+		var v int64
+		if v, err = evalInt(def); nil != err {
+			break
+		}
+		switch se.Sel.Name {
+		case "Int":
+			flags.Int(name, int(v), usage)
+		case "Uint":
+			flags.Uint(name, uint(v), usage)
+		case "Uint64":
+			flags.Uint64(name, uint64(v), usage)
+		}
+	case "String":
+		var v string
+		if v, err = evalString(def); nil == err {
+			flags.String(name, v, usage)
+		}
+	default:
+		return fmt.Errorf("unsupported flag type %s", se.Sel.Name)
+	}
+	if nil != err {
+		return fmt.Errorf("default for -%s: %w", name, err)
+	}
+
+	return nil
+}
+
+// evalString evaluates a string literal or concatenation of string literals.
+func evalString(e ast.Expr) (string, error) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if token.STRING == e.Kind {
+			return strconv.Unquote(e.Value)
+		}
+	case *ast.BinaryExpr:
+		if token.ADD != e.Op {
+			break
+		}
+		x, err := evalString(e.X)
+		if nil != err {
+			return "", err
+		}
+		y, err := evalString(e.Y)
+		if nil != err {
+			return "", err
+		}
+		return x + y, nil
+	}
+	return "", fmt.Errorf("not a string constant")
+}
+
+// evalBool evaluates true or false.
+func evalBool(e ast.Expr) (bool, error) {
+	if id, ok := e.(*ast.Ident); ok {
+		switch id.Name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("not a bool constant")
+}
+
+// evalInt evaluates an integer literal.
+func evalInt(e ast.Expr) (int64, error) {
+	if bl, ok := e.(*ast.BasicLit); ok && token.INT == bl.Kind {
+		return strconv.ParseInt(bl.Value, 0, 64)
+	}
+	return 0, fmt.Errorf("not an integer constant")
+}
+
+// evalFloat evaluates an integer or floating-point literal.
+func evalFloat(e ast.Expr) (float64, error) {
+	if bl, ok := e.(*ast.BasicLit); ok &&
+		(token.INT == bl.Kind || token.FLOAT == bl.Kind) {
+		return strconv.ParseFloat(bl.Value, 64)
+	}
+	return 0, fmt.Errorf("not a numeric constant")
+}
+
+// evalDuration evaluates an integer literal, one of the time package's units,
+// or the product of those.
+func evalDuration(e ast.Expr) (time.Duration, error) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		n, err := evalInt(e)
+		return time.Duration(n), err
+	case *ast.SelectorExpr:
+		if id, ok := e.X.(*ast.Ident); ok && "time" == id.Name {
+			if d, ok := timeUnits[e.Sel.Name]; ok {
+				return d, nil
+			}
+		}
+	case *ast.BinaryExpr:
+		if token.MUL != e.Op {
+			break
+		}
+		x, err := evalDuration(e.X)
+		if nil != err {
+			return 0, err
+		}
+		y, err := evalDuration(e.Y)
+		if nil != err {
+			return 0, err
+		}
+		return x * y, nil
+	}
+	return 0, fmt.Errorf("not a duration constant")
+}
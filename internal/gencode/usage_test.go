@@ -0,0 +1,99 @@
+package gencode
+
+/*
+ * usage_test.go
+ * Tests for usage.go
+ * By J. Stuart McMurray
+ * Created 20261019
+ * Last Modified 20261019
+ */
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDataUsage(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		data Data
+		want string
+	}{{
+		name: "periodic_envflags",
+		data: Data{
+			Name:        "cooltool",
+			Description: "Does cool things",
+			Type:        "periodic",
+			EnvFlags:    true,
+		},
+		want: `Usage: cooltool [options]
+
+Does cool things
+
+Options:
+  -count runs
+    	Number of runs to make, or 0 for no limit [$COOLTOOL_COUNT]
+  -interval interval
+    	Run interval [$COOLTOOL_INTERVAL] (default 1m0s)
+  -jitter delay
+    	Maximum random delay added to each run [$COOLTOOL_JITTER]
+  -no-summary
+    	Don't print a summary on exit [$COOLTOOL_NO_SUMMARY]
+`,
+	}, {
+		name: "library",
+		data: Data{Name: "cooltool", Type: "library"},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			c.data.SetDefaults()
+			got, err := c.data.Usage()
+			if nil != err {
+				t.Fatalf("Error: %s", err)
+			}
+			if got != c.want {
+				t.Errorf(
+					"Incorrect usage\n got:\n%s\nwant:\n%s",
+					got,
+					c.want,
+				)
+			}
+		})
+	}
+}
+
+func TestDataUsageFlags(t *testing.T) {
+	d := Data{
+		Name:     "cooltool",
+		Type:     "periodic",
+		EnvFlags: true,
+	}
+	d.SetDefaults()
+	got, err := d.UsageFlags()
+	if nil != err {
+		t.Fatalf("Error: %s", err)
+	}
+	want := []UsageFlag{{
+		Name:   "count",
+		Arg:    "runs",
+		Usage:  "Number of runs to make, or 0 for no limit",
+		EnvVar: "COOLTOOL_COUNT",
+	}, {
+		Name:    "interval",
+		Arg:     "interval",
+		Usage:   "Run interval",
+		Default: "1m0s",
+		EnvVar:  "COOLTOOL_INTERVAL",
+	}, {
+		Name:   "jitter",
+		Arg:    "delay",
+		Usage:  "Maximum random delay added to each run",
+		EnvVar: "COOLTOOL_JITTER",
+	}, {
+		Name:   "no-summary",
+		Usage:  "Don't print a summary on exit",
+		EnvVar: "COOLTOOL_NO_SUMMARY",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect flags\n got: %+v\nwant: %+v", got, want)
+	}
+}
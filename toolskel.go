@@ -6,15 +6,18 @@ package main
  * Generate command boilerplate
  * By J. Stuart McMurray
  * Created 20230204
- * Last Modified 20230427
+ * Last Modified 20261019
  */
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/user"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -33,11 +36,38 @@ func main() {
 			false,
 			"List available tool types",
 		)
+		selfCompletion = flag.String(
+			"self-completion",
+			"",
+			"Print a completion script for toolskel for "+
+				"`shell` and exit",
+		)
+		outDir = flag.String(
+			"dir",
+			"",
+			"Write files to `directory` instead of stdout",
+		)
 		tType = flag.String(
 			"type",
 			gencode.DefaultTType,
 			"Tool `type` (see -list-types)",
 		)
+		forType = flag.String(
+			"for-type",
+			gencode.DefaultTType,
+			"Tool `type` for -type test, readme, manpage "+
+				"and completion",
+		)
+		funcName = flag.String(
+			"func",
+			"Parse",
+			"Function `name` for -type fuzz to fuzz and benchmark",
+		)
+		pkgName = flag.String(
+			"package",
+			"main",
+			"Package `name` for -type fuzz",
+		)
 		author = flag.String(
 			"author",
 			defaultUsername(),
@@ -59,6 +89,141 @@ func main() {
 			false,
 			"Add a -verbose flag",
 		)
+		stream = flag.Bool(
+			"stream-tasks",
+			false,
+			"Stream parallel tasks from stdin",
+		)
+		results = flag.Bool(
+			"results",
+			false,
+			"Collect parallel tasks' results",
+		)
+		ordered = flag.Bool(
+			"ordered-results",
+			false,
+			"Print parallel tasks' results in task order",
+		)
+		rateLimit = flag.Bool(
+			"rate-limit",
+			false,
+			"Add a -rate flag to limit parallel tasks' start rate",
+		)
+		taskTimeout = flag.Bool(
+			"task-timeout",
+			false,
+			"Add a -task-timeout flag for parallel tasks",
+		)
+		retries = flag.Bool(
+			"retries",
+			false,
+			"Add -retries and -backoff flags for parallel tasks",
+		)
+		checkpoint = flag.Bool(
+			"checkpoint",
+			false,
+			"Add a -state flag to skip parallel tasks "+
+				"finished earlier",
+		)
+		progress = flag.Bool(
+			"progress",
+			false,
+			"Add a -progress flag (implies -summary-count)",
+		)
+		interrupt = flag.Bool(
+			"interrupt",
+			false,
+			"Finish up and print the summary on SIGINT/SIGTERM",
+		)
+		useContext = flag.Bool(
+			"context",
+			false,
+			"Pass functions a context cancelled on SIGINT/SIGTERM",
+		)
+		addVersion = flag.Bool(
+			"version-flag",
+			false,
+			"Add a -version flag",
+		)
+		envFlags = flag.Bool(
+			"env-flags",
+			false,
+			"Allow setting flags with environment variables",
+		)
+		configFile = flag.Bool(
+			"config-file",
+			false,
+			"Add -config and -print-config flags",
+		)
+		profiling = flag.Bool(
+			"profiling",
+			false,
+			"Add profiling, tracing and -debug-listen flags",
+		)
+		tests = flag.Bool(
+			"tests",
+			false,
+			"Also generate a _test.go file (needs -dir)",
+		)
+		readme = flag.Bool(
+			"readme",
+			false,
+			"Also generate a README.md (needs -dir)",
+		)
+		manpage = flag.Bool(
+			"manpage",
+			false,
+			"Also generate an mdoc(7) manual page (needs -dir)",
+		)
+		completion = flag.Bool(
+			"completion",
+			false,
+			"Also generate a shell completion script (needs -dir)",
+		)
+		shell = flag.String(
+			"shell",
+			gencode.DefaultShell,
+			"Completion script `shell` (bash, fish or zsh)",
+		)
+		makeFlavor = flag.String(
+			"make-flavor",
+			gencode.DefaultMakeFlavor,
+			"Makefile `flavor` for -type makefile "+
+				"(bsd, gnu or just)",
+		)
+		platforms = flag.String(
+			"platforms",
+			strings.Join(gencode.DefaultPlatforms, ","),
+			"Comma-separated `GOOS/GOARCH` pairs for "+
+				"makefile releases",
+		)
+		binPath = flag.String(
+			"service-bin",
+			"",
+			"Service binary's `path` "+
+				"(default /usr/local/bin/toolname)",
+		)
+		serviceUser = flag.String(
+			"service-user",
+			"",
+			"Service `user` (default dynamic for systemd, "+
+				"_toolname for rcd)",
+		)
+		restart = flag.String(
+			"service-restart",
+			"on-failure",
+			"Service restart `policy`, as for systemd's Restart=",
+		)
+		envFile = flag.String(
+			"service-env-file",
+			"",
+			"Optional service environment `file`",
+		)
+		useSlog = flag.Bool(
+			"slog",
+			false,
+			"Log with log/slog and add a -log-format flag",
+		)
 	)
 	flag.Usage = func() {
 		fmt.Fprintf(
@@ -81,6 +246,18 @@ Options:
 		return
 	}
 
+	/* Completion for ourselves is also easy. */
+	if "" != *selfCompletion {
+		if err := printSelfCompletion(
+			os.Stdout,
+			flag.CommandLine,
+			*selfCompletion,
+		); nil != err {
+			log.Fatalf("Error generating completion: %s", err)
+		}
+		return
+	}
+
 	/* Fill in the rest of the data for the template. */
 	data := gencode.Data{
 		Name:         flag.Arg(0),
@@ -88,6 +265,43 @@ Options:
 		TagLog:       *tagLog,
 		SummaryCount: *summaryCount,
 		Verbose:      *addVerbose,
+		Stream:       *stream,
+		Results:      *results,
+		Ordered:      *ordered,
+		RateLimit:    *rateLimit,
+		TaskTimeout:  *taskTimeout,
+		Retries:      *retries,
+		Checkpoint:   *checkpoint,
+		Progress:     *progress,
+		Interrupt:    *interrupt,
+		Context:      *useContext,
+		Slog:         *useSlog,
+		Version:      *addVersion,
+		EnvFlags:     *envFlags,
+		Config:       *configFile,
+		Profiling:    *profiling,
+		Tests:        *tests,
+		Readme:       *readme,
+		Manpage:      *manpage,
+		Completion:   *completion,
+		Shell:        *shell,
+		MakeFlavor:   *makeFlavor,
+		Platforms:    strings.Split(*platforms, ","),
+		BinPath:      *binPath,
+		ServiceUser:  *serviceUser,
+		Restart:      *restart,
+		EnvFile:      *envFile,
+		ForType:      *forType,
+		FuncName:     *funcName,
+		Package:      *pkgName,
+	}
+	if "" == data.Name && "" != *outDir {
+		/* Name the tool after its directory. */
+		ad, err := filepath.Abs(*outDir)
+		if nil != err {
+			log.Fatalf("Error resolving %s: %s", *outDir, err)
+		}
+		data.Name = filepath.Base(ad)
 	}
 	if "" != flag.Arg(1) {
 		data.Description = strings.Join(flag.Args()[1:], " ")
@@ -97,11 +311,41 @@ Options:
 	}
 
 	/* Generate the code itself. */
-	if err := gencode.Generate(os.Stdout, *tType, data); nil != err {
+	if "" != *outDir {
+		if err := gencode.GenerateDir(
+			*outDir,
+			*tType,
+			data,
+		); nil != err {
+			log.Fatalf("Error generating code: %s", err)
+		}
+		return
+	}
+	err := gencode.Generate(os.Stdout, *tType, data)
+	if errors.Is(err, gencode.ErrMultipleFiles) {
+		log.Fatalf("Error generating code: %s; use -dir", err)
+	} else if nil != err {
 		log.Fatalf("Error generating code: %s", err)
 	}
 }
 
+// printSelfCompletion writes a completion script for toolskel's flags, in fs,
+// for the given shell to w.
+func printSelfCompletion(w io.Writer, fs *flag.FlagSet, shell string) error {
+	c := gencode.FlagSetCompletion("toolskel", shell, fs)
+	for i, f := range c.Flags {
+		switch f.Name {
+		case "type", "for-type":
+			c.Flags[i].Values = gencode.TypeNames()
+		case "shell", "self-completion":
+			c.Flags[i].Values = gencode.Shells
+		case "make-flavor":
+			c.Flags[i].Values = gencode.MakeFlavors
+		}
+	}
+	return gencode.GenerateCompletion(w, c)
+}
+
 // defaultUsername returns the current user's name or username, if available.
 func defaultUsername() string {
 	u, err := user.Current()
@@ -0,0 +1,61 @@
+package main
+
+/*
+ * toolskel_test.go
+ * Tests for toolskel.go
+ * By J. Stuart McMurray
+ * Created 20261019
+ * Last Modified 20261019
+ */
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/magisterquis/toolskel/internal/gencode"
+)
+
+func TestPrintSelfCompletion(t *testing.T) {
+	/* A few of our flags, including all which have known values. */
+	fs := flag.NewFlagSet("toolskel", flag.ContinueOnError)
+	fs.String("author", "", "Author's `name`")
+	fs.String("for-type", "", "Tool `type` for -type test")
+	fs.Bool("list-types", false, "List available tool types")
+	fs.String("make-flavor", "", "Makefile `flavor`")
+	fs.String("self-completion", "", "Print a completion `shell`")
+	fs.String("shell", "", "Completion script `shell`")
+	fs.String("type", "", "Tool `type` (see -list-types)")
+
+	for _, shell := range gencode.Shells {
+		t.Run(shell, func(t *testing.T) {
+			fn := filepath.Join("_tests", "toolskel."+shell)
+			want, err := os.ReadFile(fn)
+			if nil != err {
+				t.Fatalf("Error reading wanted script: %s", err)
+			}
+			var buf bytes.Buffer
+			if err := printSelfCompletion(
+				&buf,
+				fs,
+				shell,
+			); nil != err {
+				t.Fatalf("Error: %s", err)
+			}
+			if got := buf.Bytes(); !bytes.Equal(got, want) {
+				t.Errorf(
+					"Incorrect script\ngot:\n%s\nwant:\n%s",
+					got,
+					want,
+				)
+			}
+		})
+	}
+
+	/* Unknown shells shouldn't work. */
+	if err := printSelfCompletion(&bytes.Buffer{}, fs, "csh"); nil == err {
+		t.Errorf("No error for unknown shell")
+	}
+}